@@ -0,0 +1,497 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// alertRuleConfig is one alert rule in the config file
+type alertRuleConfig struct {
+	// Rule is the condition, e.g. "cpu > 90 for 60s", "memory.available <
+	// 2GiB" or `process "postgres" absent`
+	Rule string `json:"rule"`
+	// Severity is "info", "warning" (the default) or "critical"
+	Severity string `json:"severity"`
+	// Clear is the level a firing threshold rule resolves at, in the
+	// rule's units; by default 5% of the threshold back from it
+	Clear string `json:"clear"`
+}
+
+// alertSeverity orders alerts in the banner, most severe first
+type alertSeverity int
+
+const (
+	severityInfo alertSeverity = iota
+	severityWarning
+	severityCritical
+)
+
+var severityNames = map[string]alertSeverity{
+	"info":     severityInfo,
+	"warning":  severityWarning,
+	"critical": severityCritical,
+}
+
+func (s alertSeverity) String() string {
+	for name, severity := range severityNames {
+		if severity == s {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// Banner styles by severity
+var severityStyles = map[alertSeverity]lipgloss.Style{
+	severityInfo:     lipgloss.NewStyle().Bold(true).Reverse(true).Foreground(lipgloss.Color("4")),
+	severityWarning:  lipgloss.NewStyle().Bold(true).Reverse(true).Foreground(lipgloss.Color("3")),
+	severityCritical: lipgloss.NewStyle().Bold(true).Reverse(true).Foreground(lipgloss.Color("1")),
+}
+
+// Units of alert metrics, which decide the suffixes a threshold may carry
+// and how values are shown
+const (
+	unitPercent = iota
+	unitBytes
+	unitCelsius
+)
+
+// alertMetric reads one value from a sample, reporting false when the
+// sample doesn't have it
+type alertMetric struct {
+	unit  int
+	value func(s SystemStats) (float64, bool)
+}
+
+// alertMetrics are the metrics rules can refer to by name; indexed metrics
+// such as cpu[3] and gpu[0].temp are resolved by indexedAlertMetric
+var alertMetrics = map[string]alertMetric{
+	"cpu":    {unitPercent, func(s SystemStats) (float64, bool) { return s.CPUUsage, true }},
+	"memory": {unitPercent, func(s SystemStats) (float64, bool) { return s.MemoryUsage, true }},
+	"memory.available": {unitBytes, func(s SystemStats) (float64, bool) {
+		return float64(s.MemoryDetail.Available), s.MemoryDetail.Total > 0
+	}},
+	"memory.used": {unitBytes, func(s SystemStats) (float64, bool) {
+		return float64(s.MemoryDetail.Used), s.MemoryDetail.Total > 0
+	}},
+	"swap": {unitPercent, func(s SystemStats) (float64, bool) {
+		return percentOf(s.MemoryDetail.SwapUsed, s.MemoryDetail.SwapTotal), s.MemoryDetail.SwapTotal > 0
+	}},
+	"swap.used": {unitBytes, func(s SystemStats) (float64, bool) {
+		return float64(s.MemoryDetail.SwapUsed), s.MemoryDetail.SwapTotal > 0
+	}},
+	"gpu":        {unitPercent, func(s SystemStats) (float64, bool) { return s.GPUUsage, true }},
+	"gpu.memory": {unitPercent, func(s SystemStats) (float64, bool) { return s.GPUMemory, true }},
+	"gpu.temp":   {unitCelsius, func(s SystemStats) (float64, bool) { return s.GPUTemp, s.GPUTemp > 0 }},
+	"temp": {unitCelsius, func(s SystemStats) (float64, bool) {
+		var hottest float64
+		found := false
+		for _, sensor := range s.Sensors {
+			if sensor.Kind == "temp" && (!found || sensor.Value > hottest) {
+				hottest, found = sensor.Value, true
+			}
+		}
+		return hottest, found
+	}},
+	"pressure.cpu":    pressureMetric("cpu"),
+	"pressure.memory": pressureMetric("memory"),
+	"pressure.io":     pressureMetric("io"),
+}
+
+// pressureMetric is the system-wide "some" avg10 of a PSI resource
+func pressureMetric(resource string) alertMetric {
+	return alertMetric{unitPercent, func(s SystemStats) (float64, bool) {
+		for _, p := range s.Pressure {
+			if p.Resource == resource && p.Cgroup == "" {
+				return p.Some.Avg10, true
+			}
+		}
+		return 0, false
+	}}
+}
+
+// indexedMetricPattern matches "cpu[3]", "gpu[0].temp" and "fs[/var]"
+var indexedMetricPattern = regexp.MustCompile(`^([a-z]+)\[([^\]]+)\](?:\.([a-z]+))?$`)
+
+// indexedAlertMetric resolves the metrics of one core, GPU or filesystem
+func indexedAlertMetric(name string) (alertMetric, bool) {
+	m := indexedMetricPattern.FindStringSubmatch(name)
+	if m == nil {
+		return alertMetric{}, false
+	}
+	kind, index, field := m[1], m[2], m[3]
+
+	if kind == "fs" && field == "" {
+		return alertMetric{unitPercent, func(s SystemStats) (float64, bool) {
+			for _, fs := range s.Filesystems {
+				if fs.Mountpoint == index {
+					return fs.UsedPercent, true
+				}
+			}
+			return 0, false
+		}}, true
+	}
+
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 {
+		return alertMetric{}, false
+	}
+	switch {
+	case kind == "cpu" && field == "":
+		return alertMetric{unitPercent, func(s SystemStats) (float64, bool) {
+			if i >= len(s.CPUCores) {
+				return 0, false
+			}
+			return s.CPUCores[i], true
+		}}, true
+	case kind == "gpu":
+		read := map[string]func(d GPUDevice) (float64, bool){
+			"":       func(d GPUDevice) (float64, bool) { return d.Usage, d.HasUsage },
+			"usage":  func(d GPUDevice) (float64, bool) { return d.Usage, d.HasUsage },
+			"memory": func(d GPUDevice) (float64, bool) { return d.Memory, d.HasMemory },
+			"temp":   func(d GPUDevice) (float64, bool) { return d.Temp, d.HasTemp },
+		}[field]
+		if read == nil {
+			return alertMetric{}, false
+		}
+		unit := unitPercent
+		if field == "temp" {
+			unit = unitCelsius
+		}
+		return alertMetric{unit, func(s SystemStats) (float64, bool) {
+			if i >= len(s.GPUs) {
+				return 0, false
+			}
+			return read(s.GPUs[i])
+		}}, true
+	}
+	return alertMetric{}, false
+}
+
+var (
+	// "<metric> <op> <value>[unit] [for <duration>]"
+	thresholdRulePattern = regexp.MustCompile(`^(\S+?)\s*(>=|<=|>|<)\s*([0-9.]+\s*[A-Za-z%°]*)(?:\s+for\s+(\S+))?$`)
+	// `process "<name>" absent|present [for <duration>]`
+	processRulePattern = regexp.MustCompile(`^process\s+"([^"]+)"\s+(absent|present)(?:\s+for\s+(\S+))?$`)
+	// A number and its unit suffix
+	alertValuePattern = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*([A-Za-z%°]*)$`)
+)
+
+// Byte suffixes of thresholds: K, M, G and T are binary like formatBytes,
+// with or without "iB"; KB, MB, GB and TB are decimal
+var byteSuffixes = map[string]float64{
+	"": 1, "B": 1,
+	"K": 1 << 10, "KiB": 1 << 10, "KB": 1e3,
+	"M": 1 << 20, "MiB": 1 << 20, "MB": 1e6,
+	"G": 1 << 30, "GiB": 1 << 30, "GB": 1e9,
+	"T": 1 << 40, "TiB": 1 << 40, "TB": 1e12,
+}
+
+// parseAlertValue parses a threshold with a suffix matching unit
+func parseAlertValue(s string, unit int) (float64, error) {
+	m := alertValuePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	suffix := m[2]
+	switch unit {
+	case unitPercent:
+		if suffix == "" || suffix == "%" {
+			return v, nil
+		}
+	case unitCelsius:
+		if suffix == "" || suffix == "C" || suffix == "°C" {
+			return v, nil
+		}
+	case unitBytes:
+		if multiplier, ok := byteSuffixes[suffix]; ok {
+			return v * multiplier, nil
+		}
+	}
+	return 0, fmt.Errorf("unit %q doesn't fit value %q", suffix, s)
+}
+
+// formatAlertValue shows a metric value in its unit
+func formatAlertValue(v float64, unit int) string {
+	switch unit {
+	case unitBytes:
+		return formatBytes(v)
+	case unitCelsius:
+		return fmt.Sprintf("%.0f°C", v)
+	}
+	return fmt.Sprintf("%.1f%%", v)
+}
+
+// alertRule is a parsed alertRuleConfig. Threshold rules compare a metric
+// with threshold and, once firing, resolve when it is back past clear;
+// process rules fire while a process of that name is absent, or present.
+type alertRule struct {
+	text     string
+	severity alertSeverity
+	// hold is how long the condition has to last before the rule fires
+	hold time.Duration
+
+	metric    alertMetric
+	op        string
+	threshold float64
+	clear     float64
+
+	process string
+	present bool
+
+	// gpuDevices is set for per-GPU metrics
+	gpuDevices bool
+}
+
+// parseAlertRule parses the rule, severity and clear level of c
+func parseAlertRule(c alertRuleConfig) (alertRule, error) {
+	text := strings.TrimSpace(c.Rule)
+	r := alertRule{text: text, severity: severityWarning}
+	if c.Severity != "" {
+		severity, ok := severityNames[c.Severity]
+		if !ok {
+			return r, fmt.Errorf("alert %q: unknown severity %q (expected info, warning or critical)", text, c.Severity)
+		}
+		r.severity = severity
+	}
+
+	var hold string
+	if m := processRulePattern.FindStringSubmatch(text); m != nil {
+		if c.Clear != "" {
+			return r, fmt.Errorf("alert %q: process rules have no clear level", text)
+		}
+		r.process, r.present, hold = m[1], m[2] == "present", m[3]
+	} else if m := thresholdRulePattern.FindStringSubmatch(text); m != nil {
+		metric, ok := alertMetrics[m[1]]
+		if !ok {
+			metric, ok = indexedAlertMetric(m[1])
+		}
+		if !ok {
+			return r, fmt.Errorf("alert %q: unknown metric %q", text, m[1])
+		}
+		threshold, err := parseAlertValue(m[3], metric.unit)
+		if err != nil {
+			return r, fmt.Errorf("alert %q: %w", text, err)
+		}
+		r.metric, r.op, r.threshold, hold = metric, m[2], threshold, m[4]
+		r.gpuDevices = strings.HasPrefix(m[1], "gpu[")
+
+		// Firing rules resolve once the value is back 5% of the threshold
+		// past it, so a value hovering around it doesn't flap
+		margin := threshold * 0.05
+		if r.op == ">" || r.op == ">=" {
+			margin = -margin
+		}
+		r.clear = threshold + margin
+		if c.Clear != "" {
+			if r.clear, err = parseAlertValue(c.Clear, metric.unit); err != nil {
+				return r, fmt.Errorf("alert %q: clear: %w", text, err)
+			}
+			if r.compare(r.clear, r.threshold) && r.clear != r.threshold {
+				return r, fmt.Errorf("alert %q: clear level %q is past the threshold", text, c.Clear)
+			}
+		}
+	} else {
+		return r, fmt.Errorf("invalid alert rule %q (expected e.g. \"cpu > 90 for 60s\" or `process \"name\" absent`)", text)
+	}
+
+	if hold != "" {
+		d, err := time.ParseDuration(hold)
+		if err != nil || d < 0 {
+			return r, fmt.Errorf("alert %q: invalid duration %q", text, hold)
+		}
+		r.hold = d
+	}
+	return r, nil
+}
+
+// compare applies the rule's operator to v and level
+func (r alertRule) compare(v, level float64) bool {
+	switch r.op {
+	case ">":
+		return v > level
+	case ">=":
+		return v >= level
+	case "<":
+		return v < level
+	}
+	return v <= level
+}
+
+// validateAlertRules checks every rule parses
+func validateAlertRules(rules []alertRuleConfig) error {
+	for _, c := range rules {
+		if _, err := parseAlertRule(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Alert is a firing alert rule
+type Alert struct {
+	Rule     string
+	Severity alertSeverity
+	// Value is the current value, "" for process rules
+	Value string
+	// Since is when the condition began to hold
+	Since time.Time
+}
+
+// alertState tracks one rule across samples
+type alertState struct {
+	// pending is when the condition began to hold, zero while it doesn't
+	pending time.Time
+	firing  bool
+}
+
+// alertEngine evaluates the configured rules on every sample
+type alertEngine struct {
+	mu     sync.Mutex
+	rules  []alertRule
+	states []alertState
+}
+
+var alertRules = &alertEngine{}
+
+// configure applies validated alert rules
+func (e *alertEngine) configure(rules []alertRuleConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = e.rules[:0]
+	for _, c := range rules {
+		if r, err := parseAlertRule(c); err == nil {
+			e.rules = append(e.rules, r)
+		}
+	}
+	e.states = make([]alertState, len(e.rules))
+}
+
+// usesGPUDevices reports whether a rule needs per-GPU stats, which cost an
+// extra vendor tool call per sample
+func (e *alertEngine) usesGPUDevices() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range e.rules {
+		if r.gpuDevices {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate updates every rule with the sample and returns the firing
+// alerts, most severe first. A rule fires once its condition has held for
+// its duration; a threshold rule whose metric is missing from the sample
+// resolves.
+func (e *alertEngine) evaluate(s SystemStats, now time.Time) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var firing []Alert
+	for i, r := range e.rules {
+		state := &e.states[i]
+		var active, holding bool
+		value := ""
+		if r.process != "" {
+			running := processRunning(s.Processes, r.process)
+			active = running == r.present
+			holding = active
+		} else if v, ok := r.metric.value(s); ok {
+			active = r.compare(v, r.threshold)
+			holding = r.compare(v, r.clear)
+			value = formatAlertValue(v, r.metric.unit)
+		}
+
+		switch {
+		case state.firing && !holding, !state.firing && !active:
+			*state = alertState{}
+		case !state.firing:
+			if state.pending.IsZero() {
+				state.pending = now
+			}
+			state.firing = now.Sub(state.pending) >= r.hold
+		}
+		if state.firing {
+			firing = append(firing, Alert{Rule: r.text, Severity: r.severity, Value: value, Since: state.pending})
+		}
+	}
+
+	sort.SliceStable(firing, func(i, j int) bool {
+		return firing[i].Severity > firing[j].Severity
+	})
+	return firing
+}
+
+// processRunning reports whether a process's executable, or its base
+// name, is name
+func processRunning(procs []ProcessInfo, name string) bool {
+	for _, p := range procs {
+		if p.Command == name || filepath.Base(p.Command) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Banner lines shown at most; further alerts are summed up on the last one
+const maxBannerLines = 3
+
+// bannerAlert is one line of the alert banner
+type bannerAlert struct {
+	severity alertSeverity
+	text     string
+}
+
+// renderAlertBanner returns the alerts to show above the dashboard and the
+// views: firing alert rules, the memory forecast and recent OOM kills
+func (m model) renderAlertBanner() []string {
+	var alerts []bannerAlert
+	for _, a := range m.stats.Alerts {
+		text := fmt.Sprintf(" %s: %s", strings.ToUpper(a.Severity.String()), a.Rule)
+		if a.Value != "" {
+			text += ", now " + a.Value
+		}
+		text += fmt.Sprintf(" (for %s) ", formatDuration(time.Since(a.Since).Truncate(time.Second)))
+		alerts = append(alerts, bannerAlert{a.Severity, text})
+	}
+	if f := m.stats.MemoryForecast; f.Alert {
+		text := fmt.Sprintf(" memory forecast: available memory and swap run out in %s at %s/h ",
+			formatDuration(f.ExhaustETA), formatSignedBytes((f.AvailableRate+f.SwapFreeRate)*3600))
+		alerts = append(alerts, bannerAlert{severityCritical, text})
+	}
+	if kills := recentOOMKills(m.stats.KernelLog, time.Now(), oomBannerWindow); len(kills) > 0 {
+		text := fmt.Sprintf(" OOM killer: killed %s (%d) %s ago ",
+			kills[0].Command, kills[0].PID, formatDuration(time.Since(kills[0].Time).Truncate(time.Second)))
+		if len(kills) > 1 {
+			text += fmt.Sprintf("and %d more in the last %s ", len(kills)-1, formatDuration(oomBannerWindow))
+		}
+		alerts = append(alerts, bannerAlert{severityCritical, text})
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].severity > alerts[j].severity
+	})
+
+	var lines []string
+	for i, a := range alerts {
+		if i == maxBannerLines-1 && len(alerts) > maxBannerLines {
+			text := fmt.Sprintf(" ... and %d more alerts ", len(alerts)-i)
+			lines = append(lines, severityStyles[a.severity].Render(truncateRight(text, m.width)))
+			break
+		}
+		lines = append(lines, severityStyles[a.severity].Render(truncateRight(a.text, m.width)))
+	}
+	return lines
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAlertRule(t *testing.T) {
+	tests := []struct {
+		config    alertRuleConfig
+		threshold float64
+		clear     float64
+		hold      time.Duration
+		severity  alertSeverity
+	}{
+		{alertRuleConfig{Rule: "cpu > 90 for 60s", Severity: "critical"}, 90, 85.5, time.Minute, severityCritical},
+		{alertRuleConfig{Rule: "memory.available < 2GiB", Clear: "3G"}, 2 << 30, 3 << 30, 0, severityWarning},
+		{alertRuleConfig{Rule: "memory.used>=500MB"}, 500e6, 475e6, 0, severityWarning},
+		{alertRuleConfig{Rule: "gpu[0].temp > 85°C for 2m", Severity: "info"}, 85, 80.75, 2 * time.Minute, severityInfo},
+		{alertRuleConfig{Rule: "fs[/var] >= 95%"}, 95, 90.25, 0, severityWarning},
+		{alertRuleConfig{Rule: "cpu[3] > 99"}, 99, 94.05, 0, severityWarning},
+	}
+	for _, tt := range tests {
+		r, err := parseAlertRule(tt.config)
+		if err != nil {
+			t.Errorf("parseAlertRule(%q) error = %v", tt.config.Rule, err)
+			continue
+		}
+		if r.threshold != tt.threshold || r.clear != tt.clear || r.hold != tt.hold || r.severity != tt.severity {
+			t.Errorf("parseAlertRule(%q) = threshold %v, clear %v, hold %v, severity %v; expected %v, %v, %v, %v",
+				tt.config.Rule, r.threshold, r.clear, r.hold, r.severity, tt.threshold, tt.clear, tt.hold, tt.severity)
+		}
+	}
+
+	r, err := parseAlertRule(alertRuleConfig{Rule: `process "postgres" absent for 30s`})
+	if err != nil || r.process != "postgres" || r.present || r.hold != 30*time.Second {
+		t.Errorf("parseAlertRule(process absent) = %+v, %v", r, err)
+	}
+	if r, _ := parseAlertRule(alertRuleConfig{Rule: "gpu[1] > 50"}); !r.gpuDevices {
+		t.Error("gpu[1] rule doesn't ask for per-GPU stats")
+	}
+
+	for _, bad := range []alertRuleConfig{
+		{Rule: "cpu is high"},
+		{Rule: "load > 4"},
+		{Rule: "cpu > 90GiB"},
+		{Rule: "memory.available < 2 parsecs"},
+		{Rule: "cpu > 90 for soon"},
+		{Rule: "cpu > 90", Severity: "page"},
+		{Rule: "cpu > 90", Clear: "95"},
+		{Rule: "gpu[0].fan > 50"},
+		{Rule: `process "sshd" absent`, Clear: "1"},
+	} {
+		if _, err := parseAlertRule(bad); err == nil {
+			t.Errorf("parseAlertRule(%+v) accepted an invalid rule", bad)
+		}
+	}
+}
+
+func TestAlertEngineEvaluate(t *testing.T) {
+	engine := &alertEngine{}
+	engine.configure([]alertRuleConfig{
+		{Rule: "cpu > 90 for 60s", Severity: "critical"},
+		{Rule: "memory.available < 2GiB"},
+		{Rule: `process "postgres" absent`, Severity: "info"},
+		{Rule: "gpu[0].temp > 85"},
+	})
+	if !engine.usesGPUDevices() {
+		t.Error("usesGPUDevices() = false with a gpu[0] rule")
+	}
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sample := func(cpu float64, available uint64, procs ...string) SystemStats {
+		s := SystemStats{CPUUsage: cpu, MemoryDetail: MemoryDetail{Total: 16 << 30, Available: available}}
+		for i, p := range procs {
+			s.Processes = append(s.Processes, ProcessInfo{PID: int32(i + 1), Command: p})
+		}
+		return s
+	}
+	rules := func(alerts []Alert) string {
+		var names []string
+		for _, a := range alerts {
+			names = append(names, a.Rule)
+		}
+		return strings.Join(names, "; ")
+	}
+
+	// CPU is high but not yet for a minute, memory is low, postgres runs
+	alerts := engine.evaluate(sample(95, 1<<30, "/usr/lib/postgresql/16/bin/postgres"), start)
+	if got := rules(alerts); got != "memory.available < 2GiB" {
+		t.Errorf("alerts at start = %q", got)
+	}
+	if alerts[0].Value != "1.0G" || !alerts[0].Since.Equal(start) {
+		t.Errorf("memory alert = %+v", alerts[0])
+	}
+
+	// A minute later CPU fires; memory is above 2GiB but within the clear
+	// margin, so it keeps firing; postgres is gone
+	alerts = engine.evaluate(sample(92, 2<<30+50<<20), start.Add(time.Minute))
+	if got := rules(alerts); got != `cpu > 90 for 60s; memory.available < 2GiB; process "postgres" absent` {
+		t.Errorf("alerts after a minute = %q", got)
+	}
+	if !alerts[0].Since.Equal(start) || alerts[0].Severity != severityCritical {
+		t.Errorf("CPU alert = %+v; expected critical since the start", alerts[0])
+	}
+
+	// CPU dips below 90 but stays above the 85.5 clear level, memory
+	// recovers and postgres is back
+	alerts = engine.evaluate(sample(88, 4<<30, "postgres"), start.Add(2*time.Minute))
+	if got := rules(alerts); got != "cpu > 90 for 60s" {
+		t.Errorf("alerts while recovering = %q", got)
+	}
+
+	// CPU clears and has to hold for a minute again to fire
+	engine.evaluate(sample(80, 4<<30, "postgres"), start.Add(3*time.Minute))
+	if alerts := engine.evaluate(sample(95, 4<<30, "postgres"), start.Add(4*time.Minute)); len(alerts) != 0 {
+		t.Errorf("alerts right after CPU rose again = %q", rules(alerts))
+	}
+
+	// The GPU rule fires with per-GPU stats and resolves without them
+	hot := sample(10, 4<<30, "postgres")
+	hot.GPUs = []GPUDevice{{Temp: 90, HasTemp: true}}
+	if got := rules(engine.evaluate(hot, start.Add(5*time.Minute))); got != "gpu[0].temp > 85" {
+		t.Errorf("alerts with a hot GPU = %q", got)
+	}
+	if got := rules(engine.evaluate(sample(10, 4<<30, "postgres"), start.Add(6*time.Minute))); got != "" {
+		t.Errorf("alerts without GPU stats = %q", got)
+	}
+}
+
+func TestRenderAlertBanner(t *testing.T) {
+	now := time.Now()
+	m := model{width: 120, height: 20}
+	m.stats.CPUCores = []float64{10}
+	m.stats.Alerts = []Alert{
+		{Rule: "cpu > 90 for 60s", Severity: severityCritical, Value: "97.0%", Since: now.Add(-2 * time.Minute)},
+		{Rule: `process "postgres" absent`, Severity: severityInfo, Since: now.Add(-30 * time.Second)},
+	}
+
+	lines := m.renderAlertBanner()
+	if len(lines) != 2 {
+		t.Fatalf("banner = %q; expected two lines", lines)
+	}
+	if got := stripAnsiCodes(lines[0]); !strings.Contains(got, "CRITICAL: cpu > 90 for 60s, now 97.0% (for 2m00s)") {
+		t.Errorf("first banner line = %q", got)
+	}
+	if got := stripAnsiCodes(lines[1]); !strings.Contains(got, `INFO: process "postgres" absent (for 30s)`) {
+		t.Errorf("second banner line = %q", got)
+	}
+
+	// A narrow terminal keeps the severity and rule, cutting the end
+	m.width = 24
+	if got := stripAnsiCodes(m.renderAlertBanner()[0]); got != " CRITICAL: cpu > 90 f..." {
+		t.Errorf("narrow banner line = %q; expected it cut from the right", got)
+	}
+	m.width = 120
+
+	// The memory forecast outranks the info alert, and more alerts than
+	// fit are summed up
+	m.stats.MemoryForecast = MemoryForecast{Alert: true, ExhaustETA: 25 * time.Minute, AvailableRate: -(2 << 30) / 3600.0}
+	m.stats.Alerts = append(m.stats.Alerts, Alert{Rule: "swap > 50", Severity: severityWarning, Value: "60.0%", Since: now})
+	lines = m.renderAlertBanner()
+	if len(lines) != maxBannerLines {
+		t.Fatalf("banner = %q; expected %d lines", lines, maxBannerLines)
+	}
+	if got := stripAnsiCodes(lines[1]); !strings.Contains(got, "memory forecast") {
+		t.Errorf("second banner line = %q; expected the memory forecast", got)
+	}
+	if got := stripAnsiCodes(lines[2]); !strings.Contains(got, "... and 2 more alerts") {
+		t.Errorf("last banner line = %q", got)
+	}
+
+	// Views show the banner above the tab bar and scroll below it
+	m.view = viewTopology
+	view := strings.Split(stripAnsiCodes(m.View()), "\n")
+	if !strings.Contains(view[0], "CRITICAL") || !strings.Contains(view[maxBannerLines], "Dashboard") {
+		t.Errorf("view with alerts starts with %q", view[:maxBannerLines+1])
+	}
+	if got := m.viewHeight(); got != m.height-maxBannerLines-1 {
+		t.Errorf("viewHeight() = %d; expected %d", got, m.height-maxBannerLines-1)
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Perspectives accepted by --perspective and the "perspective" config option
+const (
+	// perspectiveAuto uses the container perspective when sysmon's own
+	// cgroup has a CPU or memory limit
+	perspectiveAuto      = "auto"
+	perspectiveContainer = "container"
+	perspectiveHost      = "host"
+)
+
+// perspective is set from the config at startup
+var perspective = perspectiveAuto
+
+// validatePerspective rejects unknown perspectives
+func validatePerspective(p string) error {
+	switch p {
+	case perspectiveAuto, perspectiveContainer, perspectiveHost:
+		return nil
+	}
+	return fmt.Errorf("unknown perspective %q (expected auto, container or host)", p)
+}
+
+// Limits at or above this are the kernel's way of saying "unlimited" on
+// cgroup v1 (PAGE_COUNTER_MAX rounded to pages)
+const cgroupV1Unlimited = 1 << 62
+
+// CgroupLimits is what sysmon's own cgroup allows and uses
+type CgroupLimits struct {
+	// Version is 1 or 2, or 0 when no cgroup hierarchy was found
+	Version int
+	// MemoryUsed is the working set: usage minus inactive page cache, as
+	// reported by docker stats and the kubelet
+	MemoryUsed  uint64
+	MemoryLimit uint64
+	// CPUQuota is the quota in cores, 0 when unlimited
+	CPUQuota float64
+	// CPUSetCores is the number of CPUs in the cpuset, 0 when unknown
+	CPUSetCores int
+	// CPUUsageUsec is the cumulative CPU time of the cgroup
+	CPUUsageUsec uint64
+}
+
+// Limited reports whether the cgroup has a CPU or memory limit
+func (l CgroupLimits) Limited() bool {
+	return l.MemoryLimit > 0 || l.CPUQuota > 0
+}
+
+// EffectiveCores is the CPU capacity available to the cgroup: the quota,
+// capped by the cpuset and the host's cores
+func (l CgroupLimits) EffectiveCores(hostCores int) float64 {
+	cores := float64(hostCores)
+	if l.CPUSetCores > 0 && float64(l.CPUSetCores) < cores {
+		cores = float64(l.CPUSetCores)
+	}
+	if l.CPUQuota > 0 && l.CPUQuota < cores {
+		cores = l.CPUQuota
+	}
+	return cores
+}
+
+// ContainerStats holds the CPU and memory bars' values in the container
+// perspective
+type ContainerStats struct {
+	Active bool
+	// Cores is the effective core count the CPU percentage is relative to
+	Cores       float64
+	MemoryUsed  uint64
+	MemoryLimit uint64
+}
+
+// readOwnCgroupPaths returns sysmon's cgroup path per v1 controller, with
+// the v2 path under the key ""
+func readOwnCgroupPaths(procRoot string) map[string]string {
+	paths := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(procRoot, "self", "cgroup"))
+	if err != nil {
+		return paths
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			paths[controller] = fields[2]
+		}
+	}
+	return paths
+}
+
+// cgroupDir finds the directory of path under a hierarchy mounted at base.
+// Inside a cgroup namespace, or when only the container's own cgroup is
+// mounted, the path doesn't exist under base and base itself is the cgroup.
+func cgroupDir(base, path string) string {
+	dir := filepath.Join(base, path)
+	if _, err := os.Stat(dir); err == nil {
+		return dir
+	}
+	return base
+}
+
+// readCgroupLimits reads the limits and usage of sysmon's own cgroup from
+// the v2 hierarchy at cgRoot, or the v1 controllers mounted below it
+func readCgroupLimits(procRoot, cgRoot string) CgroupLimits {
+	paths := readOwnCgroupPaths(procRoot)
+
+	if _, err := os.Stat(filepath.Join(cgRoot, "cgroup.controllers")); err == nil {
+		return readCgroupV2Limits(cgroupDir(cgRoot, paths[""]))
+	}
+	if _, err := os.Stat(filepath.Join(cgRoot, "memory")); err == nil {
+		return readCgroupV1Limits(cgRoot, paths)
+	}
+	return CgroupLimits{}
+}
+
+func readCgroupV2Limits(dir string) CgroupLimits {
+	l := CgroupLimits{Version: 2}
+
+	current := readCgroupUint(filepath.Join(dir, "memory.current"))
+	inactive := readCgroupStat(filepath.Join(dir, "memory.stat"), "inactive_file")
+	l.MemoryUsed = subtractFloor(current, inactive)
+	l.MemoryLimit = readCgroupUint(filepath.Join(dir, "memory.max"))
+
+	// cpu.max is "<quota> <period>" or "max <period>"
+	if fields := strings.Fields(readSysfsString(filepath.Join(dir, "cpu.max"))); len(fields) == 2 {
+		quota, errQuota := strconv.ParseFloat(fields[0], 64)
+		period, errPeriod := strconv.ParseFloat(fields[1], 64)
+		if errQuota == nil && errPeriod == nil && period > 0 {
+			l.CPUQuota = quota / period
+		}
+	}
+	l.CPUSetCores = len(parseCPUList(readSysfsString(filepath.Join(dir, "cpuset.cpus.effective"))))
+	l.CPUUsageUsec = readCgroupStat(filepath.Join(dir, "cpu.stat"), "usage_usec")
+	return l
+}
+
+func readCgroupV1Limits(cgRoot string, paths map[string]string) CgroupLimits {
+	l := CgroupLimits{Version: 1}
+
+	memory := cgroupDir(filepath.Join(cgRoot, "memory"), paths["memory"])
+	usage := readCgroupUint(filepath.Join(memory, "memory.usage_in_bytes"))
+	inactive := readCgroupStat(filepath.Join(memory, "memory.stat"), "total_inactive_file")
+	l.MemoryUsed = subtractFloor(usage, inactive)
+	if limit := readCgroupUint(filepath.Join(memory, "memory.limit_in_bytes")); limit < cgroupV1Unlimited {
+		l.MemoryLimit = limit
+	}
+
+	cpuCgroup := cgroupDir(filepath.Join(cgRoot, "cpu"), paths["cpu"])
+	quota, errQuota := strconv.ParseFloat(readSysfsString(filepath.Join(cpuCgroup, "cpu.cfs_quota_us")), 64)
+	period, errPeriod := strconv.ParseFloat(readSysfsString(filepath.Join(cpuCgroup, "cpu.cfs_period_us")), 64)
+	if errQuota == nil && errPeriod == nil && quota > 0 && period > 0 {
+		l.CPUQuota = quota / period
+	}
+
+	cpuset := cgroupDir(filepath.Join(cgRoot, "cpuset"), paths["cpuset"])
+	cpus := readSysfsString(filepath.Join(cpuset, "cpuset.effective_cpus"))
+	if cpus == "" {
+		cpus = readSysfsString(filepath.Join(cpuset, "cpuset.cpus"))
+	}
+	l.CPUSetCores = len(parseCPUList(cpus))
+
+	// cpuacct.usage is in nanoseconds
+	cpuacct := cgroupDir(filepath.Join(cgRoot, "cpuacct"), paths["cpuacct"])
+	l.CPUUsageUsec = readCgroupUint(filepath.Join(cpuacct, "cpuacct.usage")) / 1000
+	return l
+}
+
+// readCgroupUint reads a single-value cgroup file; "max" and missing files
+// read as 0
+func readCgroupUint(path string) uint64 {
+	v, _ := strconv.ParseUint(readSysfsString(path), 10, 64)
+	return v
+}
+
+// readCgroupStat returns the value of key in a flat-keyed file such as
+// memory.stat or cpu.stat
+func readCgroupStat(path, key string) uint64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			v, _ := strconv.ParseUint(fields[1], 10, 64)
+			return v
+		}
+	}
+	return 0
+}
+
+// subtractFloor returns a-b, or 0 when b is larger
+func subtractFloor(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
+
+// cgroupCPUCollector turns the cgroup's cumulative CPU time into usage
+type cgroupCPUCollector struct {
+	mu        sync.Mutex
+	prevUsage uint64
+	prevTime  time.Time
+}
+
+var cgroupCPUStats = &cgroupCPUCollector{}
+
+// sample returns the cores used since the previous call; the first call
+// only records a baseline
+func (c *cgroupCPUCollector) sample(usageUsec uint64, now time.Time) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prevUsage, prevTime := c.prevUsage, c.prevTime
+	c.prevUsage, c.prevTime = usageUsec, now
+	if prevTime.IsZero() {
+		return 0
+	}
+
+	elapsed := now.Sub(prevTime).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return counterDelta(usageUsec, prevUsage) / 1e6 / elapsed
+}
+
+// containerStats resolves the perspective and, for the container one,
+// returns coresUsed as a percentage of the cgroup's effective cores and its
+// memory usage as a percentage of its limit (or of hostMemory when it has
+// none)
+func containerStats(mode string, limits CgroupLimits, coresUsed float64, hostCores int, hostMemory uint64) (ContainerStats, float64, float64) {
+	if limits.Version == 0 || mode == perspectiveHost || (mode == perspectiveAuto && !limits.Limited()) {
+		return ContainerStats{}, 0, 0
+	}
+
+	stats := ContainerStats{
+		Active:      true,
+		Cores:       limits.EffectiveCores(hostCores),
+		MemoryUsed:  limits.MemoryUsed,
+		MemoryLimit: limits.MemoryLimit,
+	}
+	if stats.MemoryLimit == 0 || (hostMemory > 0 && stats.MemoryLimit > hostMemory) {
+		stats.MemoryLimit = hostMemory
+	}
+
+	var cpuPercent float64
+	if stats.Cores > 0 {
+		cpuPercent = coresUsed / stats.Cores * 100
+		if cpuPercent > 100 {
+			cpuPercent = 100
+		}
+	}
+	return stats, cpuPercent, percentOf(stats.MemoryUsed, stats.MemoryLimit)
+}
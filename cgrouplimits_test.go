@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCgroupLimits(t *testing.T) {
+	tests := []struct {
+		dir  string
+		want CgroupLimits
+	}{
+		{"v2", CgroupLimits{Version: 2, MemoryUsed: 402653184, MemoryLimit: 1 << 30, CPUQuota: 1.5, CPUSetCores: 4, CPUUsageUsec: 123456789}},
+		// Only the container's own cgroup is mounted and nothing is limited
+		{"v2-namespace", CgroupLimits{Version: 2, MemoryUsed: 100000000, CPUUsageUsec: 42}},
+		// An unlimited v1 memory limit reads as the huge PAGE_COUNTER_MAX value
+		{"v1", CgroupLimits{Version: 1, MemoryUsed: 262144000, CPUQuota: 2, CPUSetCores: 3, CPUUsageUsec: 5000000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			root := filepath.Join("testdata", "cgroup", tt.dir)
+			got := readCgroupLimits(filepath.Join(root, "proc"), filepath.Join(root, "sys", "fs", "cgroup"))
+			if got != tt.want {
+				t.Errorf("readCgroupLimits = %+v; expected %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := readCgroupLimits(t.TempDir(), t.TempDir()); got.Version != 0 {
+		t.Errorf("readCgroupLimits without a hierarchy = %+v; expected version 0", got)
+	}
+}
+
+func TestEffectiveCores(t *testing.T) {
+	tests := []struct {
+		limits CgroupLimits
+		host   int
+		want   float64
+	}{
+		{CgroupLimits{}, 16, 16},
+		{CgroupLimits{CPUQuota: 1.5}, 16, 1.5},
+		{CgroupLimits{CPUSetCores: 4}, 16, 4},
+		{CgroupLimits{CPUQuota: 8, CPUSetCores: 4}, 16, 4},
+		{CgroupLimits{CPUQuota: 32}, 16, 16},
+	}
+	for _, tt := range tests {
+		if got := tt.limits.EffectiveCores(tt.host); got != tt.want {
+			t.Errorf("%+v.EffectiveCores(%d) = %v; expected %v", tt.limits, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestCgroupCPUCollectorSample(t *testing.T) {
+	c := &cgroupCPUCollector{}
+	start := time.Unix(1000, 0)
+	if got := c.sample(1000000, start); got != 0 {
+		t.Errorf("first sample = %v; expected a baseline of 0", got)
+	}
+	// 3 CPU seconds in 2 seconds is 1.5 cores
+	if got := c.sample(4000000, start.Add(2*time.Second)); got != 1.5 {
+		t.Errorf("sample = %v; expected 1.5 cores", got)
+	}
+}
+
+func TestContainerStats(t *testing.T) {
+	limited := CgroupLimits{Version: 2, MemoryUsed: 512 << 20, MemoryLimit: 1 << 30, CPUQuota: 2}
+	unlimited := CgroupLimits{Version: 2, MemoryUsed: 512 << 20}
+	const host = 8 << 30
+
+	tests := []struct {
+		name     string
+		mode     string
+		limits   CgroupLimits
+		active   bool
+		cpu      float64
+		memory   float64
+		cores    float64
+		memLimit uint64
+	}{
+		{"auto with limits", perspectiveAuto, limited, true, 75, 50, 2, 1 << 30},
+		{"auto without limits", perspectiveAuto, unlimited, false, 0, 0, 0, 0},
+		{"host forced", perspectiveHost, limited, false, 0, 0, 0, 0},
+		{"container forced without limits", perspectiveContainer, unlimited, true, 1.5 / 16 * 100, 6.25, 16, host},
+		{"no cgroups", perspectiveContainer, CgroupLimits{}, false, 0, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, cpu, memory := containerStats(tt.mode, tt.limits, 1.5, 16, host)
+			if stats.Active != tt.active {
+				t.Fatalf("Active = %v; expected %v", stats.Active, tt.active)
+			}
+			if math.Abs(cpu-tt.cpu) > 1e-9 || math.Abs(memory-tt.memory) > 1e-9 {
+				t.Errorf("cpu, memory = %.2f%%, %.2f%%; expected %.2f%%, %.2f%%", cpu, memory, tt.cpu, tt.memory)
+			}
+			if stats.Cores != tt.cores || stats.MemoryLimit != tt.memLimit {
+				t.Errorf("cores, limit = %v, %d; expected %v, %d", stats.Cores, stats.MemoryLimit, tt.cores, tt.memLimit)
+			}
+		})
+	}
+}
+
+func TestViewContainerLabels(t *testing.T) {
+	m := model{
+		width:  80,
+		height: 24,
+		stats: SystemStats{
+			CPUUsage:    75,
+			MemoryUsage: 50,
+			Container:   ContainerStats{Active: true, Cores: 1.5, MemoryLimit: 1 << 30},
+		},
+	}
+	view := stripAnsiCodes(m.View())
+	for _, want := range []string{"CPU Usage (1.5 cores)", "Memory (limit 1.0G)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+}
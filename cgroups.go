@@ -0,0 +1,398 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CgroupNode is one cgroup of the v2 hierarchy with its usage
+type CgroupNode struct {
+	// Path is relative to the cgroup root, "/" for the root itself
+	Path        string
+	Depth       int
+	HasChildren bool
+	// CPUCores is the CPU time used per second since the previous sample
+	CPUCores      float64
+	MemoryCurrent uint64
+	// MemoryMax is 0 when unlimited
+	MemoryMax      uint64
+	ReadBytesPerS  float64
+	WriteBytesPerS float64
+	Pids           uint64
+	Pressure       []Pressure
+	Procs          []CgroupProc
+	usageUsec      uint64
+	readBytes      uint64
+	writeBytes     uint64
+	hasMemoryFiles bool
+	hasPidsCurrent bool
+}
+
+// CgroupProc is a process that is a direct member of a cgroup
+type CgroupProc struct {
+	PID     int32
+	Command string
+}
+
+// CgroupTree is the cgroup hierarchy in depth-first order
+type CgroupTree struct {
+	// Root is where the hierarchy was read from
+	Root  string
+	Nodes []CgroupNode
+}
+
+// cgroupTreeMsg carries a fresh cgroup tree to the model
+type cgroupTreeMsg CgroupTree
+
+func updateCgroupTree() tea.Cmd {
+	return func() tea.Msg {
+		nodes := readCgroupTree(cgroupRoot(), procfsRoot)
+		cgroupTreeStats.sample(nodes, time.Now())
+		return cgroupTreeMsg(CgroupTree{Root: cgroupRoot(), Nodes: nodes})
+	}
+}
+
+// readCgroupTree walks the cgroup v2 hierarchy at cgRoot, returning nothing
+// when cgRoot isn't one. Member process names are read from procRoot.
+func readCgroupTree(cgRoot, procRoot string) []CgroupNode {
+	if _, err := os.Stat(filepath.Join(cgRoot, "cgroup.controllers")); err != nil {
+		return nil
+	}
+
+	var nodes []CgroupNode
+	filepath.WalkDir(cgRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(cgRoot, path)
+		node := CgroupNode{Path: "/"}
+		if rel != "." {
+			node.Path = "/" + filepath.ToSlash(rel)
+			node.Depth = strings.Count(node.Path, "/")
+		}
+		readCgroupNode(&node, path, procRoot)
+		nodes = append(nodes, node)
+		return nil
+	})
+
+	for i := range nodes {
+		nodes[i].HasChildren = i+1 < len(nodes) && nodes[i+1].Depth > nodes[i].Depth
+	}
+	return nodes
+}
+
+// readCgroupNode fills in the counters and members of the cgroup at dir.
+// The root cgroup has no memory.current or pids.current.
+func readCgroupNode(node *CgroupNode, dir, procRoot string) {
+	node.usageUsec = readCgroupStat(filepath.Join(dir, "cpu.stat"), "usage_usec")
+	node.readBytes, node.writeBytes = readIOStat(filepath.Join(dir, "io.stat"))
+
+	if current := readSysfsString(filepath.Join(dir, "memory.current")); current != "" {
+		node.hasMemoryFiles = true
+		node.MemoryCurrent, _ = strconv.ParseUint(current, 10, 64)
+		node.MemoryMax = readCgroupUint(filepath.Join(dir, "memory.max"))
+	}
+	if pids := readSysfsString(filepath.Join(dir, "pids.current")); pids != "" {
+		node.hasPidsCurrent = true
+		node.Pids, _ = strconv.ParseUint(pids, 10, 64)
+	}
+
+	for _, resource := range pressureResources {
+		p, err := readPressureFile(filepath.Join(dir, resource+".pressure"))
+		if err != nil {
+			continue
+		}
+		p.Resource = resource
+		p.Cgroup = node.Path
+		node.Pressure = append(node.Pressure, p)
+	}
+
+	for _, field := range strings.Fields(readSysfsString(filepath.Join(dir, "cgroup.procs"))) {
+		pid, err := strconv.ParseInt(field, 10, 32)
+		if err != nil {
+			continue
+		}
+		node.Procs = append(node.Procs, CgroupProc{PID: int32(pid), Command: readComm(procRoot, int32(pid))})
+	}
+}
+
+// readIOStat sums rbytes and wbytes over every device in an io.stat file,
+// whose lines look like "8:0 rbytes=1024 wbytes=0 rios=1 wios=0 ..."
+func readIOStat(path string) (rbytes, wbytes uint64) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		for _, field := range strings.Fields(line) {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			v, _ := strconv.ParseUint(value, 10, 64)
+			switch key {
+			case "rbytes":
+				rbytes += v
+			case "wbytes":
+				wbytes += v
+			}
+		}
+	}
+	return rbytes, wbytes
+}
+
+// cgroupCounters are the cumulative counters a cgroup's rates come from
+type cgroupCounters struct {
+	usageUsec  uint64
+	readBytes  uint64
+	writeBytes uint64
+}
+
+// cgroupTreeCollector turns the cumulative counters of every cgroup into
+// rates
+type cgroupTreeCollector struct {
+	mu       sync.Mutex
+	prev     map[string]cgroupCounters
+	prevTime time.Time
+}
+
+var cgroupTreeStats = &cgroupTreeCollector{}
+
+// sample sets the CPU and I/O rates of nodes from the counters recorded by
+// the previous call. Cgroups that weren't there before keep zero rates.
+func (c *cgroupTreeCollector) sample(nodes []CgroupNode, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev, prevTime := c.prev, c.prevTime
+	c.prev = make(map[string]cgroupCounters, len(nodes))
+	for _, n := range nodes {
+		c.prev[n.Path] = cgroupCounters{n.usageUsec, n.readBytes, n.writeBytes}
+	}
+	c.prevTime = now
+
+	elapsed := now.Sub(prevTime).Seconds()
+	if prevTime.IsZero() || elapsed <= 0 {
+		return
+	}
+	for i := range nodes {
+		p, ok := prev[nodes[i].Path]
+		if !ok {
+			continue
+		}
+		nodes[i].CPUCores = counterDelta(nodes[i].usageUsec, p.usageUsec) / 1e6 / elapsed
+		nodes[i].ReadBytesPerS = counterDelta(nodes[i].readBytes, p.readBytes) / elapsed
+		nodes[i].WriteBytesPerS = counterDelta(nodes[i].writeBytes, p.writeBytes) / elapsed
+	}
+}
+
+// cgroupRow is one line of the cgroup view: a cgroup, or one of the member
+// processes of an expanded cgroup
+type cgroupRow struct {
+	node int
+	// proc indexes the node's Procs, -1 for the cgroup itself
+	proc int
+}
+
+// cgroupRows lists the visible rows. The root's children are always shown;
+// below that a cgroup's children and member processes are shown once it is
+// expanded.
+func (m model) cgroupRows() []cgroupRow {
+	var rows []cgroupRow
+	hideBelow := -1
+	for i, n := range m.cgroups.Nodes {
+		if hideBelow >= 0 && n.Depth > hideBelow {
+			continue
+		}
+		hideBelow = -1
+		rows = append(rows, cgroupRow{node: i, proc: -1})
+
+		expanded := m.cgroupExpanded[n.Path]
+		if expanded {
+			for j := range n.Procs {
+				rows = append(rows, cgroupRow{node: i, proc: j})
+			}
+		}
+		if !expanded && n.Depth > 0 {
+			hideBelow = n.Depth
+		}
+	}
+	return rows
+}
+
+// handleCgroupKey moves the selection and expands or collapses cgroups
+func handleCgroupKey(m model, key string) (model, bool) {
+	rows := m.cgroupRows()
+	page := m.viewHeight()
+
+	switch key {
+	case "up", "k":
+		m.cgroupCursor--
+	case "down", "j":
+		m.cgroupCursor++
+	case "pgup":
+		m.cgroupCursor -= page
+	case "pgdown", " ":
+		m.cgroupCursor += page
+	case "home", "g":
+		m.cgroupCursor = 0
+	case "end", "G":
+		m.cgroupCursor = len(rows) - 1
+	case "enter", "right", "left":
+		if m.cgroupCursor < 0 || m.cgroupCursor >= len(rows) {
+			return m, true
+		}
+		row := rows[m.cgroupCursor]
+		path := m.cgroups.Nodes[row.node].Path
+		if m.cgroupExpanded == nil {
+			m.cgroupExpanded = make(map[string]bool)
+		}
+		switch {
+		case row.proc >= 0 && key == "left":
+			// Back to the cgroup the process belongs to
+			m.cgroupCursor -= row.proc + 1
+		case row.proc >= 0:
+		case key == "right":
+			m.cgroupExpanded[path] = true
+		case key == "left" && !m.cgroupExpanded[path]:
+			m.cgroupCursor = parentRow(m, rows, m.cgroupCursor)
+		case key == "left":
+			delete(m.cgroupExpanded, path)
+		default:
+			m.cgroupExpanded[path] = !m.cgroupExpanded[path]
+		}
+	default:
+		return m, false
+	}
+
+	if m.cgroupCursor >= len(rows) {
+		m.cgroupCursor = len(rows) - 1
+	}
+	if m.cgroupCursor < 0 {
+		m.cgroupCursor = 0
+	}
+
+	// Keep the selection on screen; line 0 is the header
+	line := m.cgroupCursor + 1
+	if line-1 < m.scroll {
+		m.scroll = line - 1
+	}
+	if line >= m.scroll+page {
+		m.scroll = line - page + 1
+	}
+	return m, true
+}
+
+// parentRow returns the row of the parent of the cgroup at row i, or i for
+// the top level
+func parentRow(m model, rows []cgroupRow, i int) int {
+	depth := m.cgroups.Nodes[rows[i].node].Depth
+	for j := i - 1; j >= 0; j-- {
+		if rows[j].proc < 0 && m.cgroups.Nodes[rows[j].node].Depth < depth {
+			return j
+		}
+	}
+	return i
+}
+
+// Fixed columns of the cgroup view: CPU% (6) + MEMORY (8) + MAX (8) +
+// READ/s (9) + WRITE/s (9) + PIDS (6) + PSI cpu, mem, io (5 each) + spacing
+// (10) = 71
+const cgroupFixedColumns = 71
+
+// renderCgroupView shows the cgroup tree with the usage of each cgroup
+func renderCgroupView(m model) []string {
+	if len(m.cgroups.Nodes) == 0 {
+		if m.cgroups.Root == "" {
+			return []string{"(reading the cgroup hierarchy)"}
+		}
+		return []string{fmt.Sprintf("(no cgroup v2 hierarchy at %s)", m.cgroups.Root)}
+	}
+
+	nameWidth := m.width - cgroupFixedColumns
+	if nameWidth < 20 {
+		nameWidth = 20
+	}
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	selectedStyle := lipgloss.NewStyle().Reverse(true)
+	faintStyle := lipgloss.NewStyle().Faint(true)
+
+	lines := []string{headerStyle.Render(fmt.Sprintf("%-*s %6s %8s %8s %9s %9s %6s  %5s %5s %5s",
+		nameWidth, "CGROUP", "CPU%", "MEMORY", "MAX", "READ/s", "WRITE/s", "PIDS", "P:CPU", "P:MEM", "P:IO"))}
+
+	for i, row := range m.cgroupRows() {
+		n := m.cgroups.Nodes[row.node]
+		selected := i == m.cgroupCursor
+		if row.proc >= 0 {
+			p := n.Procs[row.proc]
+			line := fmt.Sprintf("%-*s", nameWidth, truncateLeft(fmt.Sprintf("%s  %d %s", strings.Repeat("  ", n.Depth+1), p.PID, p.Command), nameWidth))
+			if selected {
+				lines = append(lines, selectedStyle.Render(line))
+			} else {
+				lines = append(lines, faintStyle.Render(line))
+			}
+			continue
+		}
+		line := cgroupNodeLine(n, m.cgroupExpanded[n.Path], nameWidth, selected)
+		if selected {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", "enter expands a cgroup and lists its processes, ← collapses; P: is PSI some avg10")
+	return lines
+}
+
+// cgroupNodeLine renders one cgroup's row, indented by its depth. The
+// selected row is left uncolored so it can be highlighted as a whole.
+func cgroupNodeLine(n CgroupNode, expanded bool, nameWidth int, selected bool) string {
+	marker := " "
+	if n.HasChildren || len(n.Procs) > 0 {
+		marker = "▸"
+		if expanded || (n.Depth == 0 && n.HasChildren) {
+			marker = "▾"
+		}
+	}
+	name := filepath.Base(n.Path)
+	name = truncateLeft(strings.Repeat("  ", n.Depth)+marker+" "+name, nameWidth)
+
+	memory, limit, pids := "-", "-", "-"
+	memoryStyle := lipgloss.NewStyle()
+	if n.hasMemoryFiles {
+		memory = formatBytes(float64(n.MemoryCurrent))
+		if n.MemoryMax > 0 {
+			limit = formatBytes(float64(n.MemoryMax))
+			if !selected {
+				memoryStyle = getColorStyle(percentOf(n.MemoryCurrent, n.MemoryMax))
+			}
+		}
+	}
+	if n.hasPidsCurrent {
+		pids = strconv.FormatUint(n.Pids, 10)
+	}
+
+	return fmt.Sprintf("%-*s %6.1f %s %8s %9s %9s %6s  %5s %5s %5s",
+		nameWidth, name, n.CPUCores*100, memoryStyle.Render(fmt.Sprintf("%8s", memory)), limit,
+		formatBytes(n.ReadBytesPerS)+"/s", formatBytes(n.WriteBytesPerS)+"/s", pids,
+		cgroupPressure(n, "cpu"), cgroupPressure(n, "memory"), cgroupPressure(n, "io"))
+}
+
+// cgroupPressure formats the some avg10 of a resource, or "-" without PSI
+func cgroupPressure(n CgroupNode, resource string) string {
+	for _, p := range n.Pressure {
+		if p.Resource == resource {
+			return fmt.Sprintf("%.1f", p.Some.Avg10)
+		}
+	}
+	return "-"
+}
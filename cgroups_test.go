@@ -0,0 +1,130 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestReadCgroupTree(t *testing.T) {
+	nodes := readCgroupTree(filepath.Join("testdata", "cgroup", "tree"), filepath.Join("testdata", "proc"))
+
+	var paths []string
+	for _, n := range nodes {
+		paths = append(paths, n.Path)
+	}
+	want := "/ /system.slice /system.slice/dnsmasq.service /system.slice/nginx.service /user.slice" +
+		" /user.slice/user-1000.slice /user.slice/user-1000.slice/user@1000.service" +
+		" /user.slice/user-1000.slice/user@1000.service/app.slice /user.slice/user-1000.slice/user@1000.service/app.slice/dbus.service"
+	if got := strings.Join(paths, " "); got != want {
+		t.Fatalf("readCgroupTree paths = %q; expected %q", got, want)
+	}
+
+	root, slice, nginx, user := nodes[0], nodes[1], nodes[3], nodes[4]
+	if !root.HasChildren || !slice.HasChildren || nginx.HasChildren || !user.HasChildren {
+		t.Errorf("HasChildren = %v %v %v %v; expected true true false true",
+			root.HasChildren, slice.HasChildren, nginx.HasChildren, user.HasChildren)
+	}
+	if root.Depth != 0 || slice.Depth != 1 || nginx.Depth != 2 {
+		t.Errorf("depths = %d %d %d; expected 0 1 2", root.Depth, slice.Depth, nginx.Depth)
+	}
+	if root.hasMemoryFiles || root.hasPidsCurrent {
+		t.Error("the root cgroup has no memory.current or pids.current")
+	}
+	if nginx.MemoryCurrent != 128<<20 || nginx.MemoryMax != 256<<20 || nginx.Pids != 2 {
+		t.Errorf("nginx memory %d of %d with %d pids; expected 128M of 256M with 2", nginx.MemoryCurrent, nginx.MemoryMax, nginx.Pids)
+	}
+	if slice.MemoryMax != 0 {
+		t.Errorf("memory.max of \"max\" read as %d; expected 0", slice.MemoryMax)
+	}
+	// io.stat is summed over devices
+	if slice.readBytes != 2<<20 || slice.writeBytes != 2<<20 {
+		t.Errorf("system.slice io = %d read, %d written; expected 2M each", slice.readBytes, slice.writeBytes)
+	}
+	if len(nginx.Procs) != 2 || nginx.Procs[0] != (CgroupProc{PID: 100, Command: "nginx"}) || nginx.Procs[1].Command != "envoy" {
+		t.Errorf("nginx.service procs = %+v; expected nginx and envoy", nginx.Procs)
+	}
+	if got := cgroupPressure(root, "cpu"); got != "12.5" {
+		t.Errorf("root cpu pressure = %q; expected 12.5", got)
+	}
+	if got := cgroupPressure(slice, "cpu"); got != "-" {
+		t.Errorf("system.slice cpu pressure = %q; expected - without a cpu.pressure file", got)
+	}
+
+	if nodes := readCgroupTree(t.TempDir(), t.TempDir()); nodes != nil {
+		t.Errorf("readCgroupTree without cgroup.controllers = %+v; expected nothing", nodes)
+	}
+}
+
+func TestCgroupTreeCollectorSample(t *testing.T) {
+	c := &cgroupTreeCollector{}
+	start := time.Unix(1000, 0)
+
+	first := []CgroupNode{{Path: "/a", usageUsec: 1000000, readBytes: 0}}
+	c.sample(first, start)
+	if first[0].CPUCores != 0 {
+		t.Errorf("first sample CPU = %v; expected a baseline of 0", first[0].CPUCores)
+	}
+
+	second := []CgroupNode{
+		{Path: "/a", usageUsec: 4000000, readBytes: 4096},
+		{Path: "/new", usageUsec: 9000000},
+	}
+	c.sample(second, start.Add(2*time.Second))
+	if second[0].CPUCores != 1.5 || second[0].ReadBytesPerS != 2048 {
+		t.Errorf("/a = %v cores, %v B/s read; expected 1.5 cores and 2048", second[0].CPUCores, second[0].ReadBytesPerS)
+	}
+	if second[1].CPUCores != 0 {
+		t.Errorf("a new cgroup got %v cores; expected 0 until its second sample", second[1].CPUCores)
+	}
+}
+
+func TestCgroupViewExpand(t *testing.T) {
+	nodes := readCgroupTree(filepath.Join("testdata", "cgroup", "tree"), filepath.Join("testdata", "proc"))
+	m := model{width: 120, height: 20, view: viewCgroups, cgroups: CgroupTree{Root: "testdata", Nodes: nodes}}
+
+	press := func(key tea.KeyMsg) {
+		next, _ := m.Update(key)
+		m = next.(model)
+	}
+	view := func() string { return stripAnsiCodes(m.View()) }
+
+	// The root's children are shown, the services below system.slice aren't
+	if !strings.Contains(view(), "system.slice") || strings.Contains(view(), "nginx.service") {
+		t.Fatalf("collapsed view:\n%s", view())
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	if m.cgroupCursor != 3 || !m.cgroupExpanded["/system.slice/nginx.service"] {
+		t.Fatalf("cursor %d, expanded %v; expected nginx.service at row 3 to be expanded", m.cgroupCursor, m.cgroupExpanded)
+	}
+	out := view()
+	for _, want := range []string{"dnsmasq.service", "100 nginx", "200 envoy", "128.0M", "256.0M"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expanded view is missing %q:\n%s", want, out)
+		}
+	}
+
+	// From a process, left goes back to its cgroup, then collapses it
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyLeft})
+	if m.cgroupCursor != 3 {
+		t.Errorf("left from a process moved to row %d; expected its cgroup at 3", m.cgroupCursor)
+	}
+	press(tea.KeyMsg{Type: tea.KeyLeft})
+	if strings.Contains(view(), "200 envoy") {
+		t.Errorf("left should collapse nginx.service:\n%s", view())
+	}
+	press(tea.KeyMsg{Type: tea.KeyLeft})
+	if m.cgroupCursor != 1 {
+		t.Errorf("left on a collapsed cgroup moved to row %d; expected its parent at 1", m.cgroupCursor)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Where sysfs and procfs are mounted. Tests point these at fixture trees.
+var (
+	sysfsRoot  = "/sys"
+	procfsRoot = "/proc"
+)
+
+// config holds user settings. Values come from the JSON config file and can
+// be overridden by command-line flags.
+type config struct {
+	// GPU selects the GPU backends: "auto", "none", or a comma-separated
+	// list of "nvidia", "amd" and "sysfs"
+	GPU string `json:"gpu"`
+	// Panels lists the optional dashboard panels shown at startup
+	Panels []string `json:"panels"`
+	// Filesystems filters the mounts listed in the filesystem panel
+	Filesystems filesystemFilter `json:"filesystems"`
+	// PressureCgroups lists cgroups, relative to the cgroup v2 root, whose
+	// PSI files the pressure panel shows
+	PressureCgroups []string `json:"pressure_cgroups"`
+	// ProcRoot and SysRoot are where procfs and sysfs are read from, e.g.
+	// /host/proc when monitoring the host from a container
+	ProcRoot string `json:"proc_root"`
+	SysRoot  string `json:"sys_root"`
+	// Perspective is "container" to show CPU and memory against sysmon's
+	// own cgroup limits, "host" for the whole machine, or "auto"
+	Perspective string `json:"perspective"`
+	// ProcessSmaps reads PSS, USS and swap of every process from
+	// /proc/<pid>/smaps_rollup
+	ProcessSmaps bool `json:"process_smaps"`
+	// LeakDetector sets how long and how fast a process's memory has to
+	// grow to be listed in the leaks panel
+	LeakDetector leakConfig `json:"leak_detector"`
+	// MemoryForecast sets the memory trend window and how soon a forecast
+	// exhaustion raises an alert
+	MemoryForecast forecastConfig `json:"memory_forecast"`
+	// KernelLog is read for OOM kills, segfaults, hung tasks and thermal
+	// events: /dev/kmsg, or a file such as /var/log/kern.log; "" disables it
+	KernelLog string `json:"kernel_log"`
+	// Alerts are the alert rules evaluated on every sample
+	Alerts []alertRuleConfig `json:"alerts"`
+}
+
+func defaultConfig() config {
+	return config{
+		GPU:            "auto",
+		ProcRoot:       "/proc",
+		SysRoot:        "/sys",
+		Perspective:    perspectiveAuto,
+		LeakDetector:   defaultLeakConfig(),
+		MemoryForecast: defaultForecastConfig(),
+		KernelLog:      "/dev/kmsg",
+	}
+}
+
+// defaultConfigPath returns $XDG_CONFIG_HOME/sysmon/config.json (or the
+// platform equivalent), or "" if no config directory is known
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sysmon", "config.json")
+}
+
+// loadConfig reads the config file at path on top of the defaults. A missing
+// file is not an error unless the path was given explicitly.
+func loadConfig(path string, explicit bool) (config, error) {
+	cfg := defaultConfig()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// parseFlags loads the config file and applies command-line overrides
+func parseFlags(args []string, output io.Writer) (config, error) {
+	fset := flag.NewFlagSet("sysmon", flag.ContinueOnError)
+	fset.SetOutput(output)
+
+	configPath := fset.String("config", defaultConfigPath(), "path to the JSON config file")
+	gpu := fset.String("gpu", "", "GPU backends: auto, none, or a comma-separated list of nvidia, amd, sysfs")
+	procRoot := fset.String("proc-root", "", "where procfs is mounted (default /proc)")
+	sysRoot := fset.String("sys-root", "", "where sysfs is mounted (default /sys)")
+	perspectiveFlag := fset.String("perspective", "", "CPU and memory relative to: auto, container (sysmon's cgroup limits) or host")
+	kernelLogPath := fset.String("kernel-log", "", "kernel log to read events from, e.g. /var/log/kern.log (default /dev/kmsg)")
+	smaps := fset.Bool("smaps", false, "read PSS, USS and swap of every process from /proc/<pid>/smaps_rollup")
+
+	if err := fset.Parse(args); err != nil {
+		return config{}, err
+	}
+
+	explicitConfig := false
+	fset.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			explicitConfig = true
+		}
+	})
+
+	cfg, err := loadConfig(*configPath, explicitConfig)
+	if err != nil {
+		return cfg, err
+	}
+
+	if *gpu != "" {
+		cfg.GPU = *gpu
+	}
+	if *procRoot != "" {
+		cfg.ProcRoot = *procRoot
+	}
+	if *sysRoot != "" {
+		cfg.SysRoot = *sysRoot
+	}
+	if *perspectiveFlag != "" {
+		cfg.Perspective = *perspectiveFlag
+	}
+	if *smaps {
+		cfg.ProcessSmaps = true
+	}
+	if *kernelLogPath != "" {
+		cfg.KernelLog = *kernelLogPath
+	}
+
+	if _, err := parseGPUMode(cfg.GPU); err != nil {
+		return cfg, err
+	}
+	if err := validatePanels(cfg.Panels); err != nil {
+		return cfg, err
+	}
+	if err := cfg.Filesystems.validate(); err != nil {
+		return cfg, err
+	}
+	if err := validatePerspective(cfg.Perspective); err != nil {
+		return cfg, err
+	}
+	if err := cfg.LeakDetector.validate(); err != nil {
+		return cfg, err
+	}
+	if err := cfg.MemoryForecast.validate(); err != nil {
+		return cfg, err
+	}
+	if err := validateAlertRules(cfg.Alerts); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// validatePanels rejects panel names that don't match a known panel
+func validatePanels(names []string) error {
+	known := make(map[string]bool)
+	for _, name := range panelNames() {
+		known[name] = true
+	}
+	for _, name := range names {
+		if !known[name] {
+			return fmt.Errorf("unknown panel %q (expected one of %s)", name, strings.Join(panelNames(), ", "))
+		}
+	}
+	return nil
+}
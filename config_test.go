@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFlags(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"gpu": "sysfs"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := parseFlags([]string{"--config", path}, io.Discard)
+	if err != nil {
+		t.Fatalf("parseFlags() error = %v", err)
+	}
+	if cfg.GPU != "sysfs" {
+		t.Errorf("cfg.GPU = %q; expected the config file value \"sysfs\"", cfg.GPU)
+	}
+
+	cfg, err = parseFlags([]string{"--config", path, "--gpu", "nvidia,amd"}, io.Discard)
+	if err != nil {
+		t.Fatalf("parseFlags() error = %v", err)
+	}
+	if cfg.GPU != "nvidia,amd" {
+		t.Errorf("cfg.GPU = %q; expected the flag to override the config file", cfg.GPU)
+	}
+
+	// A missing default config file is fine, a missing explicit one is not
+	if _, err := loadConfig(filepath.Join(dir, "missing.json"), false); err != nil {
+		t.Errorf("loadConfig(missing, implicit) error = %v; expected nil", err)
+	}
+	if _, err := parseFlags([]string{"--config", filepath.Join(dir, "missing.json")}, io.Discard); err == nil {
+		t.Error("parseFlags() with a missing explicit config succeeded; expected an error")
+	}
+
+	if _, err := parseFlags([]string{"--config", path, "--gpu", "voodoo"}, io.Discard); err == nil {
+		t.Error("parseFlags() accepted an unknown GPU backend")
+	}
+
+	if cfg.Perspective != perspectiveAuto {
+		t.Errorf("cfg.Perspective = %q; expected the default %q", cfg.Perspective, perspectiveAuto)
+	}
+	cfg, err = parseFlags([]string{"--config", path, "--perspective", "host"}, io.Discard)
+	if err != nil || cfg.Perspective != perspectiveHost {
+		t.Errorf("parseFlags(--perspective host) = %q, %v; expected host", cfg.Perspective, err)
+	}
+	if _, err := parseFlags([]string{"--config", path, "--perspective", "guest"}, io.Discard); err == nil {
+		t.Error("parseFlags() accepted an unknown perspective")
+	}
+
+	if cfg.ProcessSmaps {
+		t.Error("cfg.ProcessSmaps is on by default")
+	}
+	if cfg, err := parseFlags([]string{"--config", path, "--smaps"}, io.Discard); err != nil || !cfg.ProcessSmaps {
+		t.Errorf("parseFlags(--smaps) = %v, %v; expected smaps_rollup to be read", cfg.ProcessSmaps, err)
+	}
+
+	if cfg.KernelLog != "/dev/kmsg" {
+		t.Errorf("cfg.KernelLog = %q; expected /dev/kmsg by default", cfg.KernelLog)
+	}
+	if cfg, err := parseFlags([]string{"--config", path, "--kernel-log", "/var/log/kern.log"}, io.Discard); err != nil || cfg.KernelLog != "/var/log/kern.log" {
+		t.Errorf("parseFlags(--kernel-log) = %q, %v; expected /var/log/kern.log", cfg.KernelLog, err)
+	}
+
+	leaks := filepath.Join(dir, "leaks.json")
+	if err := os.WriteFile(leaks, []byte(`{"leak_detector": {"window": "2h"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = parseFlags([]string{"--config", leaks}, io.Discard)
+	if err != nil || cfg.LeakDetector != (leakConfig{Window: "2h", MinGrowthMiBPerHour: 10}) {
+		t.Errorf("parseFlags(leak window only) = %+v, %v; expected the default growth to be kept", cfg.LeakDetector, err)
+	}
+
+	alerts := filepath.Join(dir, "alerts.json")
+	if err := os.WriteFile(alerts, []byte(`{"alerts": [{"rule": "cpu > 90 for 60s", "severity": "critical"}, {"rule": "process \"postgres\" absent"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := parseFlags([]string{"--config", alerts}, io.Discard); err != nil || len(cfg.Alerts) != 2 || cfg.Alerts[0].Severity != "critical" {
+		t.Errorf("parseFlags(alerts) = %+v, %v", cfg.Alerts, err)
+	}
+	if err := os.WriteFile(alerts, []byte(`{"alerts": [{"rule": "cpu > ninety"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseFlags([]string{"--config", alerts}, io.Discard); err == nil {
+		t.Error("parseFlags() accepted an invalid alert rule")
+	}
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Connection is one socket from /proc/net/{tcp,tcp6,udp,udp6,unix}
+type Connection struct {
+	Proto  string
+	Local  string
+	Remote string
+	State  string
+	// Port is the local port, 0 for unix sockets
+	Port  int
+	Inode uint64
+	// PID owns the socket; 0 when no owner could be found
+	PID     int32
+	Command string
+}
+
+// ConnectionsSnapshot is the data behind the connections view
+type ConnectionsSnapshot struct {
+	Connections []Connection
+	// Unreadable counts processes whose fds could not be inspected, which
+	// usually means sysmon isn't running as root
+	Unreadable int
+}
+
+// connectionsMsg carries a fresh snapshot to the model
+type connectionsMsg ConnectionsSnapshot
+
+// TCP states as encoded in /proc/net/tcp
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+// __SO_ACCEPTCON in /proc/net/unix flags marks a listening socket
+const unixAcceptCon = 0x10000
+
+func updateConnections() tea.Cmd {
+	return func() tea.Msg {
+		return connectionsMsg(collectConnections(procfsRoot))
+	}
+}
+
+// collectConnections reads every socket table under root and attributes
+// sockets to processes via their fd symlinks
+func collectConnections(root string) ConnectionsSnapshot {
+	var conns []Connection
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		conns = append(conns, readInetSockets(filepath.Join(root, "net", proto), proto)...)
+	}
+	conns = append(conns, readUnixSockets(filepath.Join(root, "net", "unix"))...)
+
+	owners, unreadable := socketOwners(root)
+	listeners := make(map[string]int32)
+	for i := range conns {
+		if pid, ok := owners[conns[i].Inode]; ok && conns[i].Inode != 0 {
+			conns[i].PID = pid
+			if conns[i].State == "LISTEN" {
+				listeners[listenerKey(conns[i])] = pid
+			}
+		}
+	}
+
+	// TIME_WAIT and other orphaned TCP sockets have no inode; on the server
+	// side they belong to whoever listens on the same local port
+	for i := range conns {
+		if conns[i].PID == 0 && conns[i].Port != 0 {
+			conns[i].PID = listeners[listenerKey(conns[i])]
+		}
+	}
+
+	commands := make(map[int32]string)
+	for i := range conns {
+		pid := conns[i].PID
+		if pid == 0 {
+			continue
+		}
+		if _, ok := commands[pid]; !ok {
+			commands[pid] = readComm(root, pid)
+		}
+		conns[i].Command = commands[pid]
+	}
+
+	return ConnectionsSnapshot{Connections: conns, Unreadable: unreadable}
+}
+
+func listenerKey(c Connection) string {
+	return strings.TrimSuffix(c.Proto, "6") + ":" + strconv.Itoa(c.Port)
+}
+
+// readInetSockets parses one of /proc/net/{tcp,tcp6,udp,udp6}
+func readInetSockets(path, proto string) []Connection {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var conns []Connection
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+
+		local, port, err := parseHexAddr(fields[1])
+		if err != nil {
+			continue
+		}
+		remote, remotePort, err := parseHexAddr(fields[2])
+		if err != nil {
+			continue
+		}
+		inode, _ := strconv.ParseUint(fields[9], 10, 64)
+
+		state := tcpStates[fields[3]]
+		if strings.HasPrefix(proto, "udp") {
+			// UDP sockets are either bound (07) or connected (01)
+			state = "UNCONN"
+			if fields[3] == "01" {
+				state = "ESTABLISHED"
+			}
+		}
+
+		conns = append(conns, Connection{
+			Proto:  proto,
+			Local:  formatAddrPort(local, port),
+			Remote: formatAddrPort(remote, remotePort),
+			State:  state,
+			Port:   port,
+			Inode:  inode,
+		})
+	}
+	return conns
+}
+
+// parseHexAddr decodes "0100007F:1F90" style addresses. The address is
+// stored as 32-bit words in host (little-endian) byte order.
+func parseHexAddr(s string) (netip.Addr, int, error) {
+	hexAddr, hexPort, ok := strings.Cut(s, ":")
+	if !ok {
+		return netip.Addr{}, 0, fmt.Errorf("malformed address %q", s)
+	}
+
+	raw, err := hex.DecodeString(hexAddr)
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return netip.Addr{}, 0, fmt.Errorf("malformed address %q", s)
+	}
+	for i := 0; i < len(raw); i += 4 {
+		raw[i], raw[i+1], raw[i+2], raw[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return netip.Addr{}, 0, fmt.Errorf("malformed port in %q", s)
+	}
+
+	addr, _ := netip.AddrFromSlice(raw)
+	return addr.Unmap(), int(port), nil
+}
+
+// formatAddrPort renders an address like ss does: "*" for unspecified
+// hosts and ports, brackets around IPv6 hosts
+func formatAddrPort(addr netip.Addr, port int) string {
+	host := addr.String()
+	if addr.IsUnspecified() {
+		host = "*"
+	} else if addr.Is6() {
+		host = "[" + host + "]"
+	}
+
+	portStr := "*"
+	if port != 0 {
+		portStr = strconv.Itoa(port)
+	}
+	return host + ":" + portStr
+}
+
+// readUnixSockets parses /proc/net/unix
+func readUnixSockets(path string) []Connection {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var conns []Connection
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		// Num RefCount Protocol Flags Type St Inode [Path]
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 7 {
+			continue
+		}
+
+		flags, _ := strconv.ParseUint(fields[3], 16, 32)
+		inode, _ := strconv.ParseUint(fields[6], 10, 64)
+		path := "*"
+		if len(fields) > 7 {
+			path = fields[7]
+		}
+
+		state := "UNCONN"
+		if flags&unixAcceptCon != 0 {
+			state = "LISTEN"
+		} else if fields[5] == "03" {
+			state = "ESTABLISHED"
+		}
+
+		conns = append(conns, Connection{
+			Proto: "unix",
+			Local: path,
+			State: state,
+			Inode: inode,
+		})
+	}
+	return conns
+}
+
+// socketOwners maps socket inodes to the PID holding them by reading the
+// fd symlinks under <root>/<pid>/fd. It also returns how many processes
+// could not be inspected because of permissions.
+func socketOwners(root string) (map[uint64]int32, int) {
+	owners := make(map[uint64]int32)
+	unreadable := 0
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return owners, 0
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+
+		fdDir := filepath.Join(root, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			if os.IsPermission(err) {
+				unreadable++
+			}
+			continue
+		}
+
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil {
+				continue
+			}
+			// Socket fds link to "socket:[<inode>]"
+			inodeStr, ok := strings.CutPrefix(target, "socket:[")
+			if !ok {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(inodeStr, "]"), 10, 64)
+			if err != nil {
+				continue
+			}
+			if _, taken := owners[inode]; !taken {
+				owners[inode] = int32(pid)
+			}
+		}
+	}
+
+	return owners, unreadable
+}
+
+// readComm returns the short command name of pid
+func readComm(root string, pid int32) string {
+	data, err := os.ReadFile(filepath.Join(root, strconv.Itoa(int(pid)), "comm"))
+	if err != nil {
+		return "?"
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// listeningPorts returns the LISTEN TCP sockets and bound UDP sockets,
+// sorted by port
+func (s ConnectionsSnapshot) listeningPorts() []Connection {
+	var ports []Connection
+	for _, c := range s.Connections {
+		if c.Proto == "unix" {
+			continue
+		}
+		if c.State == "LISTEN" || (c.State == "UNCONN" && c.Port != 0) {
+			ports = append(ports, c)
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Proto < ports[j].Proto
+	})
+	return ports
+}
+
+// processConnections summarizes the sockets owned by one process
+type processConnections struct {
+	PID     int32
+	Command string
+	ByState map[string]int
+	Total   int
+	Sockets []Connection
+}
+
+// byProcess groups connections per owning PID, busiest first. Sockets
+// without a known owner are grouped under PID 0.
+func (s ConnectionsSnapshot) byProcess() []processConnections {
+	groups := make(map[int32]*processConnections)
+	for _, c := range s.Connections {
+		g, ok := groups[c.PID]
+		if !ok {
+			g = &processConnections{PID: c.PID, Command: c.Command, ByState: make(map[string]int)}
+			if c.PID == 0 {
+				g.Command = "(no process)"
+			}
+			groups[c.PID] = g
+		}
+		g.ByState[c.State]++
+		g.Total++
+		g.Sockets = append(g.Sockets, c)
+	}
+
+	result := make([]processConnections, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].PID < result[j].PID
+	})
+	return result
+}
+
+// renderConnectionsView lists listening ports, then every process with a
+// per-state socket count followed by its sockets
+func renderConnectionsView(m model) []string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	boldStyle := lipgloss.NewStyle().Bold(true)
+
+	snap := m.connections
+	var lines []string
+
+	lines = append(lines, boldStyle.Render("Listening ports"))
+	lines = append(lines, headerStyle.Render(fmt.Sprintf("%-5s %-40s %-10s %s", "PROTO", "ADDRESS", "PID", "COMMAND")))
+	for _, c := range snap.listeningPorts() {
+		lines = append(lines, fmt.Sprintf("%-5s %-40s %-10s %s", c.Proto, c.Local, formatPID(c.PID), c.Command))
+	}
+	lines = append(lines, "")
+
+	lines = append(lines, boldStyle.Render("Connections by process"))
+	lines = append(lines, headerStyle.Render(fmt.Sprintf("%-5s %-40s %-40s %s", "PROTO", "LOCAL", "REMOTE", "STATE")))
+	for _, p := range snap.byProcess() {
+		lines = append(lines, boldStyle.Render(fmt.Sprintf("%s %s: %d sockets (%s)",
+			formatPID(p.PID), p.Command, p.Total, formatStateCounts(p.ByState))))
+		for _, c := range p.Sockets {
+			lines = append(lines, fmt.Sprintf("%-5s %-40s %-40s %s",
+				c.Proto, truncateLeft(c.Local, 40), truncateLeft(c.Remote, 40), c.State))
+		}
+	}
+
+	if snap.Unreadable > 0 {
+		lines = append(lines, "", fmt.Sprintf("%d processes could not be inspected; run as root to see every socket owner", snap.Unreadable))
+	}
+	return lines
+}
+
+func formatPID(pid int32) string {
+	if pid == 0 {
+		return "-"
+	}
+	return strconv.Itoa(int(pid))
+}
+
+// formatStateCounts renders state counts busiest first, e.g. "TIME_WAIT 4000, ESTABLISHED 12"
+func formatStateCounts(byState map[string]int) string {
+	states := make([]string, 0, len(byState))
+	for state := range byState {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if byState[states[i]] != byState[states[j]] {
+			return byState[states[i]] > byState[states[j]]
+		}
+		return states[i] < states[j]
+	})
+
+	parts := make([]string, len(states))
+	for i, state := range states {
+		parts[i] = fmt.Sprintf("%s %d", state, byState[state])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseHexAddr(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantAddr string
+		wantPort int
+	}{
+		{"0100007F:1F90", "127.0.0.1", 8080},
+		{"00000000:0035", "0.0.0.0", 53},
+		{"00000000000000000000000001000000:01BB", "::1", 443},
+		{"0000000000000000FFFF00000100007F:0050", "127.0.0.1", 80},
+	}
+
+	for _, tt := range tests {
+		addr, port, err := parseHexAddr(tt.input)
+		if err != nil {
+			t.Errorf("parseHexAddr(%q) error = %v", tt.input, err)
+			continue
+		}
+		if addr.String() != tt.wantAddr || port != tt.wantPort {
+			t.Errorf("parseHexAddr(%q) = %s, %d; expected %s, %d", tt.input, addr, port, tt.wantAddr, tt.wantPort)
+		}
+	}
+
+	if _, _, err := parseHexAddr("garbage"); err == nil {
+		t.Error("parseHexAddr accepted a malformed address")
+	}
+}
+
+func TestCollectConnections(t *testing.T) {
+	snap := collectConnections(filepath.Join("testdata", "proc"))
+
+	var listening []string
+	for _, c := range snap.listeningPorts() {
+		listening = append(listening, c.Proto+" "+c.Local+" "+c.Command)
+	}
+	want := []string{"udp *:53 dnsmasq", "tcp6 *:443 envoy", "tcp *:8080 nginx"}
+	if strings.Join(listening, "|") != strings.Join(want, "|") {
+		t.Errorf("listeningPorts() = %v; expected %v", listening, want)
+	}
+
+	groups := snap.byProcess()
+	byPID := make(map[int32]processConnections)
+	for _, g := range groups {
+		byPID[g.PID] = g
+	}
+
+	// Server-side TIME_WAIT sockets have no inode and are attributed to the
+	// process listening on their local port
+	nginx := byPID[100]
+	if nginx.Total != 4 || nginx.ByState["TIME_WAIT"] != 2 || nginx.ByState["ESTABLISHED"] != 1 || nginx.ByState["LISTEN"] != 1 {
+		t.Errorf("nginx sockets = %+v; expected LISTEN 1, ESTABLISHED 1, TIME_WAIT 2", nginx.ByState)
+	}
+
+	// The client-side TIME_WAIT has no listener to fall back on
+	if orphans := byPID[0]; orphans.Total != 1 || orphans.ByState["TIME_WAIT"] != 1 {
+		t.Errorf("unowned sockets = %+v; expected a single TIME_WAIT", orphans.ByState)
+	}
+
+	envoy := byPID[200]
+	if envoy.Command != "envoy" || envoy.Total != 4 || envoy.ByState["LISTEN"] != 2 {
+		t.Errorf("envoy = %+v; expected 4 sockets with the tcp6 and unix listeners", envoy)
+	}
+
+	if groups[0].PID != 100 && groups[0].PID != 200 {
+		t.Errorf("byProcess()[0] = PID %d; expected the busiest process first", groups[0].PID)
+	}
+}
+
+func TestConnectionsViewRenders(t *testing.T) {
+	m := model{
+		width:       120,
+		height:      40,
+		view:        viewConnections,
+		connections: collectConnections(filepath.Join("testdata", "proc")),
+	}
+
+	view := stripAnsiCodes(m.View())
+	for _, want := range []string{"Connections", "Listening ports", "*:8080", "nginx: 4 sockets (TIME_WAIT 2, ESTABLISHED 1, LISTEN 1)", "/run/app.sock", "[::1]:443"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected connections view to contain %q:\n%s", want, view)
+		}
+	}
+}
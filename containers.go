@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Workload is what a process belongs to, resolved from its cgroup path
+type Workload struct {
+	// Runtime is "docker", "containerd", "podman" or "crio" for containers
+	Runtime     string
+	ContainerID string
+	// PodUID is set for processes in a Kubernetes pod
+	PodUID string
+	// Unit is the innermost systemd service or scope
+	Unit string
+}
+
+// Label names the workload in the process list: the short container ID with
+// its runtime, the pod, the systemd unit, or "-" for none of them
+func (w Workload) Label() string {
+	switch {
+	case w.ContainerID != "":
+		id := w.ContainerID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		if w.Runtime == "" {
+			return id
+		}
+		return w.Runtime + ":" + id
+	case w.PodUID != "":
+		return "pod:" + w.PodUID
+	case w.Unit != "":
+		return w.Unit
+	}
+	return "-"
+}
+
+var (
+	// Scope names of container runtimes using the systemd cgroup driver, e.g.
+	// docker-<id>.scope, cri-containerd-<id>.scope, libpod-<id>.scope
+	containerScopePattern = regexp.MustCompile(`^(docker|cri-containerd|nerdctl|libpod|crio)-([0-9a-f]{64})\.scope$`)
+	// Bare container IDs as used by the cgroupfs driver
+	containerIDPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	// Pod directories, "pod<uid>" with the systemd driver turning the
+	// dashes of the UID into underscores
+	podPattern = regexp.MustCompile(`^(?:kubepods-(?:besteffort-|burstable-)?)?pod([0-9a-f_-]{36})(?:\.slice)?$`)
+)
+
+// Runtimes by scope prefix
+var scopeRuntimes = map[string]string{
+	"docker":         "docker",
+	"cri-containerd": "containerd",
+	"nerdctl":        "containerd",
+	"libpod":         "podman",
+	"crio":           "crio",
+}
+
+// classifyCgroup resolves a cgroup path such as
+// /kubepods.slice/kubepods-pod<uid>.slice/cri-containerd-<id>.scope
+func classifyCgroup(path string) Workload {
+	var w Workload
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if m := containerScopePattern.FindStringSubmatch(part); m != nil {
+			w.Runtime = scopeRuntimes[m[1]]
+			w.ContainerID = m[2]
+			continue
+		}
+		if m := podPattern.FindStringSubmatch(part); m != nil {
+			w.PodUID = strings.ReplaceAll(m[1], "_", "-")
+			continue
+		}
+		if containerIDPattern.MatchString(part) {
+			w.ContainerID = part
+			// /docker/<id> with the cgroupfs driver; other runtimes leave
+			// nothing but the ID
+			if i > 0 && parts[i-1] == "docker" {
+				w.Runtime = "docker"
+			}
+			continue
+		}
+		if strings.HasSuffix(part, ".service") || strings.HasSuffix(part, ".scope") {
+			w.Unit = part
+		}
+	}
+
+	// The container scope is a unit too, but the ID says more
+	if w.ContainerID != "" {
+		w.Unit = ""
+	}
+	return w
+}
+
+// parseCgroupFile returns the path to classify from /proc/<pid>/cgroup: the
+// unified hierarchy's on cgroup v2, or the name=systemd one (falling back to
+// any controller) on v1
+func parseCgroupFile(data []byte) string {
+	var unified, systemd, other string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		switch {
+		case fields[0] == "0" && fields[1] == "":
+			unified = fields[2]
+		case fields[1] == "name=systemd":
+			systemd = fields[2]
+		case other == "" && fields[2] != "/":
+			other = fields[2]
+		}
+	}
+
+	// A hybrid v1 host has a "0::" line too, but it is usually just "/"
+	for _, path := range []string{unified, systemd, other} {
+		if path != "" && path != "/" {
+			return path
+		}
+	}
+	return unified
+}
+
+// readWorkload resolves the workload of a process from <root>/<pid>/cgroup
+func readWorkload(root string, pid int32) Workload {
+	data, err := os.ReadFile(filepath.Join(root, strconv.Itoa(int(pid)), "cgroup"))
+	if err != nil {
+		return Workload{}
+	}
+	return classifyCgroup(parseCgroupFile(data))
+}
+
+// WorkloadUsage is the combined usage of the processes of one workload
+type WorkloadUsage struct {
+	Label     string
+	Processes int
+	CPU       float64
+	Memory    float64
+}
+
+// rollupWorkloads sums CPU and memory by workload label, busiest first
+func rollupWorkloads(procs []ProcessInfo) []WorkloadUsage {
+	byLabel := make(map[string]*WorkloadUsage)
+	var result []WorkloadUsage
+	var order []string
+	for _, p := range procs {
+		label := p.Workload.Label()
+		u, ok := byLabel[label]
+		if !ok {
+			u = &WorkloadUsage{Label: label}
+			byLabel[label] = u
+			order = append(order, label)
+		}
+		u.Processes++
+		u.CPU += p.CPU
+		u.Memory += float64(p.Memory)
+	}
+
+	for _, label := range order {
+		result = append(result, *byLabel[label])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].CPU != result[j].CPU {
+			return result[i].CPU > result[j].CPU
+		}
+		return result[i].Memory > result[j].Memory
+	})
+	return result
+}
+
+// renderWorkloadRows renders the header and up to n rows of the
+// per-workload rollup shown in place of the process list
+func renderWorkloadRows(workloads []WorkloadUsage, n, width int) []string {
+	header := fmt.Sprintf("%-*s %6s  %6s  %5s", workloadWidth(width), "CONTAINER / UNIT", "PROCS", "CPU%", "MEM%")
+	lines := []string{header}
+	for i := 0; i < n && i < len(workloads); i++ {
+		w := workloads[i]
+		lines = append(lines, fmt.Sprintf("%-*s %6d  %s  %s",
+			workloadWidth(width), truncateLeft(w.Label, workloadWidth(width)), w.Processes,
+			getColorStyle(w.CPU).Render(fmt.Sprintf("%6.1f", w.CPU)),
+			getColorStyle(w.Memory).Render(fmt.Sprintf("%5.1f", w.Memory))))
+	}
+	return lines
+}
+
+// workloadWidth is the width of the rollup's name column: whatever is left
+// after the number columns (PROCS 6 + CPU% 6 + MEM% 5 + spacing 5 = 22)
+func workloadWidth(width int) int {
+	w := width - 22
+	if w < minCommandWidth {
+		w = minCommandWidth
+	}
+	return w
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	testContainerID = "3f2a1b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708"
+	testPodUID      = "6b1e3c2a-4d5f-4e6a-9b7c-8d9e0f1a2b3c"
+)
+
+func TestClassifyCgroup(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want Workload
+	}{
+		{"docker systemd driver", "/system.slice/docker-" + testContainerID + ".scope",
+			Workload{Runtime: "docker", ContainerID: testContainerID}},
+		{"docker cgroupfs driver", "/docker/" + testContainerID,
+			Workload{Runtime: "docker", ContainerID: testContainerID}},
+		{"podman rootless", "/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-" + testContainerID + ".scope",
+			Workload{Runtime: "podman", ContainerID: testContainerID}},
+		{"nerdctl", "/system.slice/nerdctl-" + testContainerID + ".scope",
+			Workload{Runtime: "containerd", ContainerID: testContainerID}},
+		{"kubernetes containerd systemd driver",
+			"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod" + strings.ReplaceAll(testPodUID, "-", "_") +
+				".slice/cri-containerd-" + testContainerID + ".scope",
+			Workload{Runtime: "containerd", ContainerID: testContainerID, PodUID: testPodUID}},
+		{"kubernetes cri-o", "/kubepods.slice/kubepods-pod" + strings.ReplaceAll(testPodUID, "-", "_") + ".slice/crio-" + testContainerID + ".scope",
+			Workload{Runtime: "crio", ContainerID: testContainerID, PodUID: testPodUID}},
+		{"kubernetes cgroupfs driver", "/kubepods/besteffort/pod" + testPodUID + "/" + testContainerID,
+			Workload{ContainerID: testContainerID, PodUID: testPodUID}},
+		{"kubernetes pod without container", "/kubepods/burstable/pod" + testPodUID,
+			Workload{PodUID: testPodUID}},
+		{"systemd service", "/system.slice/nginx.service", Workload{Unit: "nginx.service"}},
+		{"user session", "/user.slice/user-1000.slice/session-3.scope", Workload{Unit: "session-3.scope"}},
+		{"nested user service", "/user.slice/user-1000.slice/user@1000.service/app.slice/pipewire.service",
+			Workload{Unit: "pipewire.service"}},
+		{"root cgroup", "/", Workload{}},
+		{"init scope", "/init.scope", Workload{Unit: "init.scope"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyCgroup(tt.path); got != tt.want {
+				t.Errorf("classifyCgroup(%q) = %+v; expected %+v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkloadLabel(t *testing.T) {
+	tests := []struct {
+		w    Workload
+		want string
+	}{
+		{Workload{Runtime: "docker", ContainerID: testContainerID}, "docker:3f2a1b4c5d6e"},
+		{Workload{ContainerID: testContainerID, PodUID: testPodUID}, "3f2a1b4c5d6e"},
+		{Workload{PodUID: testPodUID}, "pod:" + testPodUID},
+		{Workload{Unit: "nginx.service"}, "nginx.service"},
+		{Workload{}, "-"},
+	}
+	for _, tt := range tests {
+		if got := tt.w.Label(); got != tt.want {
+			t.Errorf("%+v.Label() = %q; expected %q", tt.w, got, tt.want)
+		}
+	}
+}
+
+func TestReadWorkload(t *testing.T) {
+	root := filepath.Join("testdata", "proc")
+	tests := []struct {
+		pid  int32
+		want string
+	}{
+		{100, "docker:3f2a1b4c5d6e"},
+		{200, "containerd:9e8d7c6b5a4f"},
+		// cgroup v1 with an empty unified hierarchy
+		{300, "dnsmasq.service"},
+		// No such process
+		{400, "-"},
+	}
+	for _, tt := range tests {
+		if got := readWorkload(root, tt.pid).Label(); got != tt.want {
+			t.Errorf("readWorkload(%d) = %q; expected %q", tt.pid, got, tt.want)
+		}
+	}
+}
+
+func TestRollupWorkloads(t *testing.T) {
+	docker := Workload{Runtime: "docker", ContainerID: testContainerID}
+	procs := []ProcessInfo{
+		{PID: 1, CPU: 10, Memory: 1, Workload: docker},
+		{PID: 2, CPU: 0, Memory: 2, Workload: Workload{Unit: "sshd.service"}},
+		{PID: 3, CPU: 30, Memory: 4, Workload: docker},
+		{PID: 4, CPU: 5, Memory: 8},
+		{PID: 5, CPU: 0, Memory: 3, Workload: Workload{Unit: "cron.service"}},
+	}
+
+	got := rollupWorkloads(procs)
+	want := []WorkloadUsage{
+		{Label: "docker:3f2a1b4c5d6e", Processes: 2, CPU: 40, Memory: 5},
+		{Label: "-", Processes: 1, CPU: 5, Memory: 8},
+		{Label: "cron.service", Processes: 1, CPU: 0, Memory: 3},
+		{Label: "sshd.service", Processes: 1, CPU: 0, Memory: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rollupWorkloads =\n%+v\nexpected\n%+v", got, want)
+	}
+}
+
+func TestViewProcessRollup(t *testing.T) {
+	m := model{
+		width:  100,
+		height: 24,
+		stats: SystemStats{
+			CPUCores: []float64{10},
+			Processes: []ProcessInfo{
+				{PID: 1234, CPU: 10.5, Memory: 5.2, Command: "/usr/bin/nginx", Workload: Workload{Runtime: "docker", ContainerID: testContainerID}},
+			},
+			Workloads: []WorkloadUsage{{Label: "docker:3f2a1b4c5d6e", Processes: 3, CPU: 42, Memory: 7.5}},
+		},
+	}
+
+	view := stripAnsiCodes(m.View())
+	if !strings.Contains(view, "CONTAINER") || !strings.Contains(view, "docker:3f2a1b4c5d6e       /usr/bin/nginx") {
+		t.Errorf("process list does not show the container column:\n%s", view)
+	}
+
+	m.processRollup = true
+	view = stripAnsiCodes(m.View())
+	if strings.Contains(view, "/usr/bin/nginx") || !strings.Contains(view, "docker:3f2a1b4c5d6e") || !strings.Contains(view, "     3    42.0    7.5") {
+		t.Errorf("rollup does not replace the process list:\n%s", view)
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// coreLayout selects how the per-core grid is drawn
+type coreLayout int
+
+const (
+	// coreLayoutAuto draws bars, switching to the heatmap when the bars would
+	// take more than a third of the screen
+	coreLayoutAuto coreLayout = iota
+	coreLayoutBars
+	coreLayoutHeatmap
+)
+
+// next cycles auto -> bars -> heatmap -> auto
+func (l coreLayout) next() coreLayout {
+	return (l + 1) % (coreLayoutHeatmap + 1)
+}
+
+const (
+	// Spacing between core bars on one line
+	coreBarSpacing = 2
+	// Cells per heatmap block; blocks are separated by a space
+	heatmapBlock = 8
+)
+
+// coreGroup is a set of logical CPUs shown together, e.g. one NUMA node
+type coreGroup struct {
+	label string
+	cores []int
+}
+
+// groupCores splits count cores by NUMA node, or by socket when the kernel
+// reports no nodes. Without topology for every core they form one group.
+func groupCores(topology []CPUTopology, count int) []coreGroup {
+	all := make([]int, count)
+	for i := range all {
+		all[i] = i
+	}
+	if len(topology) != count {
+		return []coreGroup{{label: "All cores", cores: all}}
+	}
+
+	byNode := true
+	for _, t := range topology {
+		if t.Node < 0 {
+			byNode = false
+			break
+		}
+	}
+
+	members := make(map[int][]int)
+	for i, t := range topology {
+		key := t.Package
+		if byNode {
+			key = t.Node
+		}
+		members[key] = append(members[key], i)
+	}
+
+	keys := make([]int, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	kind := "Socket"
+	if byNode {
+		kind = "Node"
+	}
+	groups := make([]coreGroup, len(keys))
+	for i, key := range keys {
+		groups[i] = coreGroup{label: fmt.Sprintf("%s %d", kind, key), cores: members[key]}
+	}
+	return groups
+}
+
+// coreSummary returns the average and peak usage of cores
+func coreSummary(usage []float64, cores []int) (avg, peak float64) {
+	if len(cores) == 0 {
+		return 0, 0
+	}
+	for _, c := range cores {
+		avg += usage[c]
+		if usage[c] > peak {
+			peak = usage[c]
+		}
+	}
+	return avg / float64(len(cores)), peak
+}
+
+// coreLabel names a core, padded to the width of the highest core number
+func coreLabel(core, count int) string {
+	digits := len(fmt.Sprint(count - 1))
+	if digits < 2 {
+		digits = 2
+	}
+	return fmt.Sprintf("CPU%0*d", digits, core)
+}
+
+// coreColumns returns how many core bars of at least minWidth fit in width,
+// never more than the largest group needs
+func coreColumns(width, minWidth int, groups []coreGroup) int {
+	cols := (width + coreBarSpacing) / (minWidth + coreBarSpacing)
+	largest := 0
+	for _, g := range groups {
+		if len(g.cores) > largest {
+			largest = len(g.cores)
+		}
+	}
+	if cols > largest {
+		cols = largest
+	}
+	if cols < 1 {
+		cols = 1
+	}
+	return cols
+}
+
+// renderCores draws the per-core section: bars or a heatmap, optionally
+// grouped by NUMA node, or only per-group averages when collapsed
+func (m model) renderCores(terminalHeight int) []string {
+	coreCount := len(m.stats.CPUCores)
+	if coreCount == 0 {
+		return nil
+	}
+
+	groups := groupCores(nil, coreCount)
+	if m.coreGrouped {
+		groups = groupCores(m.stats.CPUTopology, coreCount)
+	}
+
+	if m.coresCollapsed {
+		lines := make([]string, len(groups))
+		for i, g := range groups {
+			avg, peak := coreSummary(m.stats.CPUCores, g.cores)
+			label := fmt.Sprintf("%s (%d)", g.label, len(g.cores))
+			text := fmt.Sprintf("avg %5.1f%%  max %5.1f%%", avg, peak)
+			lines[i] = createBarWithText(label, text, avg, m.width, getColorStyle(avg).Underline(true))
+		}
+		return lines
+	}
+
+	// Bars must fit the label, " 100.0%" and some bar
+	minBarWidth := len(m.coreBarLabel(coreCount-1)) + 7 + 3
+	cols := coreColumns(m.width, minBarWidth, groups)
+	barWidth := (m.width - (cols-1)*coreBarSpacing) / cols
+	if barWidth < minBarWidth {
+		barWidth = minBarWidth
+	}
+
+	layout := m.coreLayout
+	if layout == coreLayoutAuto {
+		layout = coreLayoutBars
+		rows := 0
+		for _, g := range groups {
+			rows += (len(g.cores) + cols - 1) / cols
+		}
+		budget := terminalHeight / 3
+		if budget < 4 {
+			budget = 4
+		}
+		if rows > budget {
+			layout = coreLayoutHeatmap
+		}
+	}
+
+	// Detailed mode needs a breakdown for every core, which the first sample lacks
+	detailed := m.cpuDetailed && len(m.stats.CPUTimes) == coreCount && layout == coreLayoutBars
+
+	var lines []string
+	for _, g := range groups {
+		if m.coreGrouped {
+			lines = append(lines, m.coreGroupHeader(g))
+		}
+		if layout == coreLayoutHeatmap {
+			lines = append(lines, m.coreHeatmap(g.cores)...)
+		} else {
+			lines = append(lines, m.coreBars(g.cores, cols, barWidth, detailed)...)
+		}
+	}
+
+	switch {
+	case detailed:
+		lines = append(lines, renderCPUTimesLegend())
+	case layout == coreLayoutHeatmap:
+		lines = append(lines, fmt.Sprintf("one cell per core: %s 0%% … %s 100%%",
+			greenStyle.Render(string(sparkRunes[0])), redStyle.Render(string(sparkRunes[len(sparkRunes)-1]))))
+	}
+	return lines
+}
+
+// coreGroupHeader summarizes a group above its cores
+func (m model) coreGroupHeader(g coreGroup) string {
+	avg, peak := coreSummary(m.stats.CPUCores, g.cores)
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	return headerStyle.Render(fmt.Sprintf("%s  %d cores", g.label, len(g.cores))) + "  avg " +
+		getColorStyle(avg).Render(fmt.Sprintf("%.1f%%", avg)) + "  max " +
+		getColorStyle(peak).Render(fmt.Sprintf("%.1f%%", peak))
+}
+
+// coreBars draws one labelled bar per core, cols to a line
+func (m model) coreBars(cores []int, cols, barWidth int, detailed bool) []string {
+	var lines []string
+	for i := 0; i < len(cores); i += cols {
+		bars := make([]string, 0, cols)
+		for j := i; j < i+cols && j < len(cores); j++ {
+			core := cores[j]
+			percent := m.stats.CPUCores[core]
+			label := m.coreBarLabel(core)
+			if detailed {
+				bars = append(bars, createCPUTimesBar(label, m.stats.CPUTimes[core], barWidth))
+				continue
+			}
+			bars = append(bars, createBarWithText(label, fmt.Sprintf("%4.1f%%", percent), percent, barWidth,
+				getColorStyle(percent).Underline(true)))
+		}
+		lines = append(lines, strings.Join(bars, strings.Repeat(" ", coreBarSpacing)))
+	}
+	return lines
+}
+
+// coreBarLabel labels a core bar with its number and, when cpufreq reports
+// it, the current clock
+func (m model) coreBarLabel(core int) string {
+	coreCount := len(m.stats.CPUCores)
+	label := coreLabel(core, coreCount)
+	if len(m.stats.CPUFreq) == coreCount && m.stats.CPUFreq[core].Cur > 0 {
+		label += " " + formatFreq(m.stats.CPUFreq[core].Cur)
+	}
+	return label
+}
+
+// coreHeatmap draws one colored cell per core, its height showing usage. Each
+// row starts with the label of its first core.
+func (m model) coreHeatmap(cores []int) []string {
+	coreCount := len(m.stats.CPUCores)
+	prefixWidth := len(coreLabel(0, coreCount)) + 1
+	available := m.width - prefixWidth
+
+	perRow := (available + 1) / (heatmapBlock + 1) * heatmapBlock
+	if perRow == 0 {
+		perRow = available
+	}
+	if perRow < 1 {
+		perRow = 1
+	}
+
+	var lines []string
+	for i := 0; i < len(cores); i += perRow {
+		var b strings.Builder
+		b.WriteString(coreLabel(cores[i], coreCount) + " ")
+		for j := i; j < i+perRow && j < len(cores); j++ {
+			if j > i && (j-i)%heatmapBlock == 0 {
+				b.WriteString(" ")
+			}
+			percent := m.stats.CPUCores[cores[j]]
+			b.WriteString(getColorStyle(percent).Render(string(heatCell(percent))))
+		}
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// heatCell picks the block character for a usage percentage
+func heatCell(percent float64) rune {
+	idx := int(percent / 100 * float64(len(sparkRunes)-1))
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sparkRunes) {
+		idx = len(sparkRunes) - 1
+	}
+	return sparkRunes[idx]
+}
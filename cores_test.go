@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGroupCores(t *testing.T) {
+	tests := []struct {
+		name     string
+		topology []CPUTopology
+		count    int
+		want     []coreGroup
+	}{
+		{
+			name:  "no topology",
+			count: 3,
+			want:  []coreGroup{{label: "All cores", cores: []int{0, 1, 2}}},
+		},
+		{
+			name:     "interleaved NUMA nodes",
+			topology: []CPUTopology{{Package: 0, Node: 0}, {Package: 0, Node: 1}, {Package: 0, Node: 0}, {Package: 0, Node: 1}},
+			count:    4,
+			want: []coreGroup{
+				{label: "Node 0", cores: []int{0, 2}},
+				{label: "Node 1", cores: []int{1, 3}},
+			},
+		},
+		{
+			name:     "sockets without NUMA nodes",
+			topology: []CPUTopology{{Package: 1, Node: -1}, {Package: 0, Node: -1}, {Package: 1, Node: -1}},
+			count:    3,
+			want: []coreGroup{
+				{label: "Socket 0", cores: []int{1}},
+				{label: "Socket 1", cores: []int{0, 2}},
+			},
+		},
+		{
+			name:     "topology for fewer cores than reported",
+			topology: []CPUTopology{{Package: 0, Node: 0}},
+			count:    2,
+			want:     []coreGroup{{label: "All cores", cores: []int{0, 1}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := groupCores(tt.topology, tt.count); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupCores = %+v; expected %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// manyCoreModel returns a model with count cores split evenly over two nodes
+func manyCoreModel(count, width, height int) model {
+	cores := make([]float64, count)
+	topology := make([]CPUTopology, count)
+	for i := range cores {
+		cores[i] = float64(i % 100)
+		topology[i] = CPUTopology{Package: i * 2 / count, Node: i * 2 / count}
+	}
+	return model{
+		width:  width,
+		height: height,
+		stats:  SystemStats{CPUCores: cores, CPUTopology: topology},
+	}
+}
+
+func TestRenderCoresColumnsFollowWidth(t *testing.T) {
+	tests := []struct {
+		width int
+		cols  int
+	}{
+		{width: 30, cols: 1},
+		{width: 40, cols: 2},
+		{width: 80, cols: 4},
+		{width: 200, cols: 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("width %d", tt.width), func(t *testing.T) {
+			m := manyCoreModel(16, tt.width, 100)
+			m.coreLayout = coreLayoutBars
+			lines := m.renderCores(m.height)
+
+			if got := strings.Count(stripAnsiCodes(lines[0]), "CPU"); got != tt.cols {
+				t.Errorf("first line has %d cores; expected %d", got, tt.cols)
+			}
+			for _, line := range lines {
+				if n := len([]rune(stripAnsiCodes(line))); n > tt.width && tt.cols > 1 {
+					t.Errorf("line is %d wide; expected at most %d: %q", n, tt.width, stripAnsiCodes(line))
+				}
+			}
+		})
+	}
+}
+
+func TestRenderCoresFallsBackToHeatmap(t *testing.T) {
+	m := manyCoreModel(192, 80, 40)
+	lines := m.renderCores(m.height)
+
+	// 192 cells at 64 per row plus the legend line
+	if len(lines) != 4 {
+		t.Fatalf("auto layout rendered %d lines for 192 cores; expected a 3 row heatmap and a legend:\n%s",
+			len(lines), stripAnsiCodes(strings.Join(lines, "\n")))
+	}
+	if !strings.HasPrefix(stripAnsiCodes(lines[1]), "CPU064 ") {
+		t.Errorf("second heatmap row = %q; expected it to start at CPU064", stripAnsiCodes(lines[1]))
+	}
+
+	// Forcing bars keeps one bar per core
+	m.coreLayout = coreLayoutBars
+	if got := len(m.renderCores(m.height)); got != 48 {
+		t.Errorf("bars layout rendered %d lines; expected 48", got)
+	}
+}
+
+func TestRenderCoresGroupedAndCollapsed(t *testing.T) {
+	m := manyCoreModel(8, 80, 40)
+	m.coreGrouped = true
+
+	// A header per node followed by its 4 cores
+	lines := m.renderCores(m.height)
+	if len(lines) != 4 {
+		t.Fatalf("grouped layout rendered %d lines; expected 4", len(lines))
+	}
+	header := stripAnsiCodes(lines[2])
+	if !strings.HasPrefix(header, "Node 1  4 cores") || !strings.Contains(header, "avg 5.5%") {
+		t.Errorf("second group header = %q; expected node 1 with average 5.5%%", header)
+	}
+
+	m.coresCollapsed = true
+	lines = m.renderCores(m.height)
+	if len(lines) != 2 {
+		t.Fatalf("collapsed layout rendered %d lines; expected one per node", len(lines))
+	}
+	if got := stripAnsiCodes(lines[0]); !strings.Contains(got, "Node 0 (4)") || !strings.Contains(got, "max   3.0%") {
+		t.Errorf("collapsed node 0 = %q; expected its core count and peak", got)
+	}
+}
+
+func TestRenderCoresShowsFrequency(t *testing.T) {
+	m := manyCoreModel(2, 80, 24)
+	m.stats.CPUFreq = []CPUFreq{{Cur: 3200000}, {}}
+	m.coreLayout = coreLayoutBars
+
+	line := stripAnsiCodes(m.renderCores(m.height)[0])
+	if !strings.HasPrefix(line, "CPU00 3.20G ") {
+		t.Errorf("core line = %q; expected cpu0's clock after its label", line)
+	}
+	if !strings.Contains(line, "CPU01 ") || strings.Contains(line, "CPU01 0.00G") {
+		t.Errorf("core line = %q; expected no clock for cpu1, which has no cpufreq", line)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// CPUTimes is the share of one core's time spent in each state between two
+// samples, as percentages. Guest time is split out of User and Nice, where
+// the kernel also accounts it.
+type CPUTimes struct {
+	User    float64
+	Nice    float64
+	System  float64
+	Iowait  float64
+	Irq     float64
+	Softirq float64
+	Steal   float64
+	Guest   float64
+	Idle    float64
+}
+
+// Busy is the percentage of time not idle or waiting for I/O
+func (t CPUTimes) Busy() float64 {
+	return t.User + t.Nice + t.System + t.Irq + t.Softirq + t.Steal + t.Guest
+}
+
+// cpuTimeStates lists the stacked bar segments in drawing order with their
+// legend labels and colors
+var cpuTimeStates = []struct {
+	label string
+	style lipgloss.Style
+	value func(CPUTimes) float64
+}{
+	{"user", lipgloss.NewStyle().Foreground(lipgloss.Color("2")), func(t CPUTimes) float64 { return t.User }},
+	{"nice", lipgloss.NewStyle().Foreground(lipgloss.Color("4")), func(t CPUTimes) float64 { return t.Nice }},
+	{"system", lipgloss.NewStyle().Foreground(lipgloss.Color("1")), func(t CPUTimes) float64 { return t.System }},
+	{"irq", lipgloss.NewStyle().Foreground(lipgloss.Color("3")), func(t CPUTimes) float64 { return t.Irq }},
+	{"softirq", lipgloss.NewStyle().Foreground(lipgloss.Color("5")), func(t CPUTimes) float64 { return t.Softirq }},
+	{"steal", lipgloss.NewStyle().Foreground(lipgloss.Color("6")), func(t CPUTimes) float64 { return t.Steal }},
+	{"guest", lipgloss.NewStyle().Foreground(lipgloss.Color("14")), func(t CPUTimes) float64 { return t.Guest }},
+	{"iowait", lipgloss.NewStyle().Foreground(lipgloss.Color("8")), func(t CPUTimes) float64 { return t.Iowait }},
+}
+
+// cpuTimesCollector turns cumulative per-core times into per-tick shares
+type cpuTimesCollector struct {
+	mu   sync.Mutex
+	prev []cpu.TimesStat
+}
+
+var cpuTimesStats = &cpuTimesCollector{}
+
+// collect samples per-core times and returns the breakdown since the previous call
+func (c *cpuTimesCollector) collect() []CPUTimes {
+	times, err := cpu.Times(true)
+	if err != nil {
+		return nil
+	}
+	return c.sample(times)
+}
+
+// sample computes per-core shares from cumulative times. The first call,
+// and any call where the core count changed, only records a baseline.
+func (c *cpuTimesCollector) sample(times []cpu.TimesStat) []CPUTimes {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev := c.prev
+	c.prev = times
+	if len(prev) != len(times) {
+		return nil
+	}
+
+	result := make([]CPUTimes, len(times))
+	for i, cur := range times {
+		result[i] = cpuTimesDelta(prev[i], cur)
+	}
+	return result
+}
+
+// cpuTimesDelta converts the difference between two samples into percentages
+func cpuTimesDelta(prev, cur cpu.TimesStat) CPUTimes {
+	d := func(a, b float64) float64 {
+		if b < a {
+			return 0
+		}
+		return b - a
+	}
+
+	guest := d(prev.Guest, cur.Guest)
+	guestNice := d(prev.GuestNice, cur.GuestNice)
+	delta := CPUTimes{
+		User:    d(prev.User, cur.User) - guest,
+		Nice:    d(prev.Nice, cur.Nice) - guestNice,
+		System:  d(prev.System, cur.System),
+		Iowait:  d(prev.Iowait, cur.Iowait),
+		Irq:     d(prev.Irq, cur.Irq),
+		Softirq: d(prev.Softirq, cur.Softirq),
+		Steal:   d(prev.Steal, cur.Steal),
+		Guest:   guest + guestNice,
+		Idle:    d(prev.Idle, cur.Idle),
+	}
+	if delta.User < 0 {
+		delta.User = 0
+	}
+	if delta.Nice < 0 {
+		delta.Nice = 0
+	}
+
+	total := delta.User + delta.Nice + delta.System + delta.Iowait + delta.Irq +
+		delta.Softirq + delta.Steal + delta.Guest + delta.Idle
+	if total <= 0 {
+		return CPUTimes{}
+	}
+
+	scale := 100 / total
+	return CPUTimes{
+		User:    delta.User * scale,
+		Nice:    delta.Nice * scale,
+		System:  delta.System * scale,
+		Iowait:  delta.Iowait * scale,
+		Irq:     delta.Irq * scale,
+		Softirq: delta.Softirq * scale,
+		Steal:   delta.Steal * scale,
+		Guest:   delta.Guest * scale,
+		Idle:    delta.Idle * scale,
+	}
+}
+
+// createCPUTimesBar renders a core as "CPU00 <stacked bar>  45.0%" in width
+// columns. The percentage is busy time; iowait is drawn but not counted.
+func createCPUTimesBar(label string, t CPUTimes, width int) string {
+	percentText := fmt.Sprintf("%5.1f%%", t.Busy())
+	barWidth := width - len(label) - len(percentText) - 2
+	if barWidth < 1 {
+		return label + " " + percentText
+	}
+
+	segments := make([]barSegment, len(cpuTimeStates))
+	for i, state := range cpuTimeStates {
+		segments[i] = barSegment{percent: state.value(t), style: state.style}
+	}
+	return label + " " + createStackedBar(segments, barWidth) + " " + getColorStyle(t.Busy()).Render(percentText)
+}
+
+// renderCPUTimesLegend explains the stacked bar colors
+func renderCPUTimesLegend() string {
+	parts := make([]string, len(cpuTimeStates))
+	for i, state := range cpuTimeStates {
+		parts[i] = state.style.Render("█") + " " + state.label
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+func TestCPUTimesDelta(t *testing.T) {
+	prev := cpu.TimesStat{User: 100, System: 50, Idle: 800, Iowait: 10, Steal: 5, Guest: 20}
+	// 200 ticks elapsed: user 60 (of which guest 20), system 20, iowait 40,
+	// steal 30, softirq 10, idle 40
+	cur := cpu.TimesStat{User: 160, System: 70, Idle: 840, Iowait: 50, Steal: 35, Softirq: 10, Guest: 40}
+
+	got := cpuTimesDelta(prev, cur)
+	want := CPUTimes{User: 20, System: 10, Iowait: 20, Steal: 15, Softirq: 5, Guest: 10, Idle: 20}
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"User", got.User, want.User},
+		{"System", got.System, want.System},
+		{"Iowait", got.Iowait, want.Iowait},
+		{"Steal", got.Steal, want.Steal},
+		{"Softirq", got.Softirq, want.Softirq},
+		{"Guest", got.Guest, want.Guest},
+		{"Idle", got.Idle, want.Idle},
+	} {
+		if math.Abs(c.got-c.want) > 0.001 {
+			t.Errorf("%s = %v; expected %v", c.name, c.got, c.want)
+		}
+	}
+
+	// Iowait is shown but not counted as busy
+	if busy := got.Busy(); math.Abs(busy-60) > 0.001 {
+		t.Errorf("Busy() = %v; expected 60", busy)
+	}
+}
+
+func TestCPUTimesCollectorBaseline(t *testing.T) {
+	c := &cpuTimesCollector{}
+	if got := c.sample([]cpu.TimesStat{{User: 1}, {User: 1}}); got != nil {
+		t.Errorf("first sample = %v; expected nil", got)
+	}
+	if got := c.sample([]cpu.TimesStat{{User: 2, Idle: 1}, {User: 1, Idle: 1}}); len(got) != 2 || got[0].User != 50 || got[1].Idle != 100 {
+		t.Errorf("second sample = %+v", got)
+	}
+	// A hot-plugged core resets the baseline instead of misaligning cores
+	if got := c.sample([]cpu.TimesStat{{User: 3}, {User: 3}, {User: 3}}); got != nil {
+		t.Errorf("sample after a core count change = %v; expected nil", got)
+	}
+}
+
+func TestDetailedCoreBars(t *testing.T) {
+	m := model{
+		width:       80,
+		height:      24,
+		cpuDetailed: true,
+		stats: SystemStats{
+			CPUCores: []float64{60, 10},
+			CPUTimes: []CPUTimes{{User: 40, System: 10, Steal: 10, Iowait: 20, Idle: 20}, {User: 10, Idle: 90}},
+		},
+	}
+
+	view := stripAnsiCodes(m.View())
+	for _, want := range []string{"CPU00", " 60.0%", " 10.0%", "steal", "iowait"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected detailed view to contain %q:\n%s", want, view)
+		}
+	}
+
+	m.cpuDetailed = false
+	if view := stripAnsiCodes(m.View()); strings.Contains(view, "softirq") {
+		t.Error("simple mode should not show the CPU state legend")
+	}
+}
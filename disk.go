@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// DiskStats holds per-device I/O rates computed between two samples
+type DiskStats struct {
+	Name           string
+	ReadBytesPerS  float64
+	WriteBytesPerS float64
+	ReadIOPS       float64
+	WriteIOPS      float64
+	// AwaitMs is the mean time an I/O spent queued and being serviced
+	AwaitMs float64
+	// Util is the percentage of time the device had I/O in flight
+	Util float64
+}
+
+// Device name prefixes that are never real storage
+var ignoredDiskPrefixes = []string{"loop", "ram", "fd"}
+
+// diskCollector turns cumulative disk counters into rates between ticks
+type diskCollector struct {
+	mu       sync.Mutex
+	prev     map[string]disk.IOCountersStat
+	prevTime time.Time
+}
+
+var diskStats = &diskCollector{}
+
+// collect samples the kernel counters and returns rates since the previous call
+func (c *diskCollector) collect() []DiskStats {
+	counters, err := disk.IOCounters()
+	if err != nil {
+		return nil
+	}
+	return c.sample(filterDisks(counters, sysfsRoot), time.Now())
+}
+
+// sample computes rates from counters taken at now. The first call only
+// records a baseline and returns nil.
+func (c *diskCollector) sample(counters map[string]disk.IOCountersStat, now time.Time) []DiskStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev, prevTime := c.prev, c.prevTime
+	c.prev, c.prevTime = counters, now
+	if prev == nil {
+		return nil
+	}
+
+	elapsed := now.Sub(prevTime).Seconds()
+	if elapsed <= 0 {
+		return nil
+	}
+
+	var result []DiskStats
+	for name, cur := range counters {
+		old, ok := prev[name]
+		if !ok {
+			continue
+		}
+
+		reads := counterDelta(cur.ReadCount, old.ReadCount)
+		writes := counterDelta(cur.WriteCount, old.WriteCount)
+		ioMs := counterDelta(cur.ReadTime, old.ReadTime) + counterDelta(cur.WriteTime, old.WriteTime)
+
+		stats := DiskStats{
+			Name:           name,
+			ReadBytesPerS:  counterDelta(cur.ReadBytes, old.ReadBytes) / elapsed,
+			WriteBytesPerS: counterDelta(cur.WriteBytes, old.WriteBytes) / elapsed,
+			ReadIOPS:       reads / elapsed,
+			WriteIOPS:      writes / elapsed,
+			Util:           counterDelta(cur.IoTime, old.IoTime) / (elapsed * 1000) * 100,
+		}
+		if reads+writes > 0 {
+			stats.AwaitMs = ioMs / (reads + writes)
+		}
+		if stats.Util > 100 {
+			stats.Util = 100
+		}
+		result = append(result, stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// counterDelta returns cur-prev, treating a counter that went backwards
+// (device reset or wrap) as no activity
+func counterDelta(cur, prev uint64) float64 {
+	if cur < prev {
+		return 0
+	}
+	return float64(cur - prev)
+}
+
+// filterDisks drops loop and ram devices and, when sysfs is available, keeps
+// only whole devices listed in <root>/block so partitions aren't counted twice
+func filterDisks(counters map[string]disk.IOCountersStat, root string) map[string]disk.IOCountersStat {
+	blockDir := filepath.Join(root, "block")
+	_, err := os.Stat(blockDir)
+	haveSysfs := err == nil
+
+	filtered := make(map[string]disk.IOCountersStat, len(counters))
+	for name, c := range counters {
+		if hasAnyPrefix(name, ignoredDiskPrefixes) {
+			continue
+		}
+		if haveSysfs {
+			if _, err := os.Stat(filepath.Join(blockDir, name)); err != nil {
+				continue
+			}
+		}
+		filtered[name] = c
+	}
+	return filtered
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordDiskHistory appends the disk throughput samples used by the sparklines
+func recordDiskHistory(h history, disks []DiskStats) {
+	for _, d := range disks {
+		h.record("disk:"+d.Name, d.ReadBytesPerS+d.WriteBytesPerS)
+	}
+}
+
+// renderDiskPanel renders one line per device: name, %util bar, rates and a
+// throughput sparkline
+func renderDiskPanel(m model) []string {
+	// Fixed columns: 10+1+20+1+9+1+9+1+7+1+7+1+8+2 = 78
+	sparkWidth := m.width - 78
+
+	header := fmt.Sprintf("%-10s %-20s %9s %9s %7s %7s %8s",
+		"DEVICE", "UTIL", "READ/s", "WRITE/s", "r/s", "w/s", "AWAIT")
+	if sparkWidth > 0 {
+		header += "  " + truncateLeft("THROUGHPUT", sparkWidth)
+	}
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	lines := []string{headerStyle.Render(header)}
+
+	if len(m.stats.Disks) == 0 {
+		return append(lines, "(waiting for a second sample)")
+	}
+
+	for _, d := range m.stats.Disks {
+		style := getColorStyle(d.Util)
+		bar := createBarWithText("", fmt.Sprintf("%.0f%%", d.Util), d.Util, 20, style)
+		line := fmt.Sprintf("%-10s %s %9s %9s %7.0f %7.0f %6.1fms",
+			truncateLeft(d.Name, 10), bar,
+			formatBytes(d.ReadBytesPerS), formatBytes(d.WriteBytesPerS),
+			d.ReadIOPS, d.WriteIOPS, d.AwaitMs)
+		if sparkWidth > 0 {
+			line += "  " + sparkline(m.history["disk:"+d.Name].last(sparkWidth), sparkWidth, 0)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+func TestDiskCollectorSample(t *testing.T) {
+	c := &diskCollector{}
+	start := time.Unix(1000, 0)
+
+	first := map[string]disk.IOCountersStat{
+		"sda": {Name: "sda", ReadCount: 100, WriteCount: 50, ReadBytes: 1 << 20, WriteBytes: 1 << 20, ReadTime: 200, WriteTime: 100, IoTime: 1000},
+	}
+	if got := c.sample(first, start); got != nil {
+		t.Fatalf("first sample returned %+v; expected nil baseline", got)
+	}
+
+	second := map[string]disk.IOCountersStat{
+		"sda": {Name: "sda", ReadCount: 300, WriteCount: 150, ReadBytes: 1<<20 + 4<<20, WriteBytes: 1<<20 + 2<<20, ReadTime: 800, WriteTime: 400, IoTime: 2500},
+		"sdb": {Name: "sdb", ReadCount: 1},
+	}
+	got := c.sample(second, start.Add(2*time.Second))
+	if len(got) != 1 {
+		t.Fatalf("sample returned %d devices; expected only sda, which has a baseline", len(got))
+	}
+
+	d := got[0]
+	checks := []struct {
+		name      string
+		got, want float64
+	}{
+		{"ReadBytesPerS", d.ReadBytesPerS, 2 << 20},
+		{"WriteBytesPerS", d.WriteBytesPerS, 1 << 20},
+		{"ReadIOPS", d.ReadIOPS, 100},
+		{"WriteIOPS", d.WriteIOPS, 50},
+		{"AwaitMs", d.AwaitMs, 900.0 / 300.0},
+		{"Util", d.Util, 75},
+	}
+	for _, c := range checks {
+		if math.Abs(c.got-c.want) > 0.001 {
+			t.Errorf("%s = %v; expected %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestFilterDisks(t *testing.T) {
+	root := t.TempDir()
+	for _, dev := range []string{"sda", "nvme0n1"} {
+		if err := os.MkdirAll(filepath.Join(root, "block", dev), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counters := map[string]disk.IOCountersStat{
+		"sda": {}, "sda1": {}, "nvme0n1": {}, "nvme0n1p2": {}, "loop0": {},
+	}
+
+	filtered := filterDisks(counters, root)
+	if len(filtered) != 2 {
+		t.Errorf("filterDisks kept %v; expected sda and nvme0n1", filtered)
+	}
+
+	// Without sysfs only the loop/ram devices are dropped
+	filtered = filterDisks(counters, filepath.Join(root, "missing"))
+	if _, ok := filtered["loop0"]; ok || len(filtered) != 4 {
+		t.Errorf("filterDisks without sysfs kept %v; expected everything but loop0", filtered)
+	}
+}
+
+func TestDiskPanelRenders(t *testing.T) {
+	m := model{
+		width:   120,
+		height:  40,
+		panels:  map[string]bool{"disk": true},
+		history: history{},
+		stats: SystemStats{
+			CPUCores: []float64{10, 20},
+			Disks: []DiskStats{
+				{Name: "nvme0n1", ReadBytesPerS: 3 << 20, WriteBytesPerS: 512, ReadIOPS: 40, Util: 85},
+			},
+		},
+	}
+	recordDiskHistory(m.history, m.stats.Disks)
+
+	view := stripAnsiCodes(m.View())
+	for _, want := range []string{"DEVICE", "nvme0n1", "3.0M", "85%"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected disk panel to contain %q", want)
+		}
+	}
+}
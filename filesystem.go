@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// FilesystemStats holds space and inode usage for one mounted filesystem
+type FilesystemStats struct {
+	Mountpoint   string
+	Device       string
+	Fstype       string
+	Total        uint64
+	Used         uint64
+	Free         uint64
+	UsedPercent  float64
+	InodesUsed   uint64
+	InodesTotal  uint64
+	InodePercent float64
+}
+
+// filesystemFilter selects which mounts the filesystem panel lists. Patterns
+// are shell globs matched against the mountpoint, device and filesystem type.
+// Include patterns win over both Exclude and the built-in pseudo filesystem list.
+type filesystemFilter struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+// Filesystem types hidden by default because they don't take a host down
+// when they fill up, or aren't backed by a disk at all
+var pseudoFilesystems = map[string]bool{
+	"tmpfs": true, "devtmpfs": true, "overlay": true, "squashfs": true,
+	"proc": true, "sysfs": true, "cgroup": true, "cgroup2": true,
+	"devpts": true, "mqueue": true, "debugfs": true, "tracefs": true,
+	"securityfs": true, "pstore": true, "bpf": true, "configfs": true,
+	"fusectl": true, "hugetlbfs": true, "autofs": true, "binfmt_misc": true,
+	"nsfs": true, "rpc_pipefs": true, "efivarfs": true, "ramfs": true,
+}
+
+// fsFilter is the active filesystem filter, set from the config at startup
+var fsFilter filesystemFilter
+
+// shows reports whether the filesystem panel should list p
+func (f filesystemFilter) shows(p disk.PartitionStat) bool {
+	if matchesAnyField(f.Include, p) {
+		return true
+	}
+	if pseudoFilesystems[p.Fstype] {
+		return false
+	}
+	return !matchesAnyField(f.Exclude, p)
+}
+
+func matchesAnyField(patterns []string, p disk.PartitionStat) bool {
+	for _, pattern := range patterns {
+		for _, field := range []string{p.Mountpoint, p.Device, p.Fstype} {
+			if ok, _ := filepath.Match(pattern, field); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validate checks that every pattern is a well-formed glob
+func (f filesystemFilter) validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid filesystem pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// getFilesystems returns usage for the mounted filesystems passing the filter,
+// listing each device once even when it is bind-mounted several times
+func getFilesystems(filter filesystemFilter) []FilesystemStats {
+	partitions, err := disk.Partitions(true)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var result []FilesystemStats
+	for _, p := range partitions {
+		if !filter.shows(p) || seen[p.Device+" "+p.Fstype] {
+			continue
+		}
+
+		usage, err := disk.Usage(p.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			continue
+		}
+		seen[p.Device+" "+p.Fstype] = true
+
+		result = append(result, FilesystemStats{
+			Mountpoint:   p.Mountpoint,
+			Device:       p.Device,
+			Fstype:       p.Fstype,
+			Total:        usage.Total,
+			Used:         usage.Used,
+			Free:         usage.Free,
+			UsedPercent:  usage.UsedPercent,
+			InodesUsed:   usage.InodesUsed,
+			InodesTotal:  usage.InodesTotal,
+			InodePercent: usage.InodesUsedPercent,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Mountpoint < result[j].Mountpoint
+	})
+	return result
+}
+
+// renderFilesystemPanel renders one line per filesystem with a usage bar
+func renderFilesystemPanel(m model) []string {
+	// Fixed columns: TYPE (8) + SIZE/USED/FREE (3x7) + INODES% (7) + spacing (6) = 42,
+	// the remainder is split between the mountpoint and the usage bar
+	remaining := m.width - 42
+	if remaining < 30 {
+		remaining = 30
+	}
+	mountWidth := remaining / 2
+	barWidth := remaining - mountWidth
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	lines := []string{headerStyle.Render(fmt.Sprintf("%-*s %-8s %7s %7s %7s %-*s %7s",
+		mountWidth, "MOUNT", "TYPE", "SIZE", "USED", "FREE", barWidth, "USE%", "INODES"))}
+
+	for _, fs := range m.stats.Filesystems {
+		bar := createBarWithText("", fmt.Sprintf("%.0f%%", fs.UsedPercent), fs.UsedPercent, barWidth, getColorStyle(fs.UsedPercent))
+		inodes := "-"
+		if fs.InodesTotal > 0 {
+			inodes = getColorStyle(fs.InodePercent).Render(fmt.Sprintf("%6.1f%%", fs.InodePercent))
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %-8s %7s %7s %7s %s %7s",
+			mountWidth, truncateLeft(fs.Mountpoint, mountWidth), truncateLeft(fs.Fstype, 8),
+			formatBytes(float64(fs.Total)), formatBytes(float64(fs.Used)), formatBytes(float64(fs.Free)),
+			bar, inodes))
+	}
+	return lines
+}
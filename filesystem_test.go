@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+func TestFilesystemFilter(t *testing.T) {
+	root := disk.PartitionStat{Device: "/dev/nvme0n1p2", Mountpoint: "/", Fstype: "ext4"}
+	boot := disk.PartitionStat{Device: "/dev/nvme0n1p1", Mountpoint: "/boot/efi", Fstype: "vfat"}
+	shm := disk.PartitionStat{Device: "tmpfs", Mountpoint: "/dev/shm", Fstype: "tmpfs"}
+	snap := disk.PartitionStat{Device: "/dev/loop3", Mountpoint: "/snap/core/123", Fstype: "squashfs"}
+	docker := disk.PartitionStat{Device: "overlay", Mountpoint: "/var/lib/docker/overlay2/abc/merged", Fstype: "overlay"}
+
+	tests := []struct {
+		name   string
+		filter filesystemFilter
+		part   disk.PartitionStat
+		want   bool
+	}{
+		{"real filesystem shown by default", filesystemFilter{}, root, true},
+		{"tmpfs hidden by default", filesystemFilter{}, shm, false},
+		{"squashfs hidden by default", filesystemFilter{}, snap, false},
+		{"overlay hidden by default", filesystemFilter{}, docker, false},
+		{"exclude by mountpoint glob", filesystemFilter{Exclude: []string{"/boot/*"}}, boot, false},
+		{"exclude by type", filesystemFilter{Exclude: []string{"vfat"}}, boot, false},
+		{"include brings back a pseudo type", filesystemFilter{Include: []string{"/dev/shm"}}, shm, true},
+		{"include wins over exclude", filesystemFilter{Include: []string{"/"}, Exclude: []string{"ext4"}}, root, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.shows(tt.part); got != tt.want {
+				t.Errorf("shows(%s) = %v; expected %v", tt.part.Mountpoint, got, tt.want)
+			}
+		})
+	}
+
+	if err := (filesystemFilter{Exclude: []string{"[bad"}}).validate(); err == nil {
+		t.Error("validate() accepted a malformed glob")
+	}
+}
+
+func TestFilesystemPanelRenders(t *testing.T) {
+	m := model{
+		width:  100,
+		height: 40,
+		panels: map[string]bool{"filesystems": true},
+		stats: SystemStats{
+			Filesystems: []FilesystemStats{
+				{Mountpoint: "/var", Fstype: "xfs", Total: 100 << 30, Used: 92 << 30, Free: 8 << 30, UsedPercent: 92, InodesTotal: 1000, InodesUsed: 10, InodePercent: 1},
+			},
+		},
+	}
+
+	view := stripAnsiCodes(m.View())
+	for _, want := range []string{"MOUNT", "/var", "xfs", "100.0G", "92%", "1.0%"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected filesystem panel to contain %q", want)
+		}
+	}
+}
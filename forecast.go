@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// forecastConfig configures the memory exhaustion forecast
+type forecastConfig struct {
+	// Window is how much memory history the trend is fitted to, as a Go
+	// duration
+	Window string `json:"window"`
+	// AlertWithin raises an alert when memory is forecast to run out
+	// sooner than this
+	AlertWithin string `json:"alert_within"`
+}
+
+func defaultForecastConfig() forecastConfig {
+	return forecastConfig{Window: "15m", AlertWithin: "1h"}
+}
+
+// validate checks both settings are positive durations
+func (c forecastConfig) validate() error {
+	for name, value := range map[string]string{"window": c.Window, "alert_within": c.AlertWithin} {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid memory forecast %s %q: %w", name, value, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("memory forecast %s %q must be positive", name, value)
+		}
+	}
+	return nil
+}
+
+// MemoryForecast is the trend of available memory and free swap
+type MemoryForecast struct {
+	// Ready is set once the samples span a third of the window
+	Ready bool
+	// AvailableRate and SwapFreeRate are in bytes/s, negative when shrinking
+	AvailableRate float64
+	SwapFreeRate  float64
+	// AvailableETA is when available memory reaches zero and ExhaustETA
+	// when free swap does too, 0 when they aren't shrinking
+	AvailableETA time.Duration
+	ExhaustETA   time.Duration
+	// Alert is set when ExhaustETA is within AlertWithin
+	Alert       bool
+	AlertWithin time.Duration
+}
+
+type memoryPoint struct {
+	at        time.Time
+	available uint64
+	swapFree  uint64
+}
+
+// memoryForecaster keeps the memory samples of the window
+type memoryForecaster struct {
+	mu          sync.Mutex
+	window      time.Duration
+	alertWithin time.Duration
+	points      []memoryPoint
+}
+
+var memoryForecast = &memoryForecaster{window: 15 * time.Minute, alertWithin: time.Hour}
+
+// configure applies a validated forecastConfig
+func (f *memoryForecaster) configure(c forecastConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.window, _ = time.ParseDuration(c.Window)
+	f.alertWithin, _ = time.ParseDuration(c.AlertWithin)
+}
+
+// update records a sample and fits a line to the window's samples
+func (f *memoryForecaster) update(d MemoryDetail, now time.Time) MemoryForecast {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.points = append(f.points, memoryPoint{at: now, available: d.Available, swapFree: subtractFloor(d.SwapTotal, d.SwapUsed)})
+	cutoff := now.Add(-f.window)
+	for len(f.points) > 0 && f.points[0].at.Before(cutoff) {
+		f.points = f.points[1:]
+	}
+
+	forecast := MemoryForecast{AlertWithin: f.alertWithin}
+	if len(f.points) < 3 || now.Sub(f.points[0].at) < f.window/3 {
+		return forecast
+	}
+
+	times := make([]time.Time, len(f.points))
+	available := make([]float64, len(f.points))
+	swapFree := make([]float64, len(f.points))
+	for i, p := range f.points {
+		times[i] = p.at
+		available[i] = float64(p.available)
+		swapFree[i] = float64(p.swapFree)
+	}
+	forecast.AvailableRate, _ = fitSlope(times, available)
+	forecast.SwapFreeRate, _ = fitSlope(times, swapFree)
+	forecast.Ready = true
+
+	// Once available memory is gone the kernel swaps, so the OOM killer
+	// runs when both are used up
+	forecast.AvailableETA = timeToZero(float64(d.Available), forecast.AvailableRate)
+	forecast.ExhaustETA = timeToZero(float64(d.Available)+float64(subtractFloor(d.SwapTotal, d.SwapUsed)),
+		forecast.AvailableRate+forecast.SwapFreeRate)
+	forecast.Alert = forecast.ExhaustETA > 0 && forecast.ExhaustETA <= f.alertWithin
+	return forecast
+}
+
+// Forecasts further out than this are noise from a nearly flat fit, and
+// beyond what a Duration holds for tiny rates
+const maxForecast = 365 * 24 * time.Hour
+
+// timeToZero is how long value takes to reach zero at rate per second, or 0
+// when it isn't falling or won't reach zero within maxForecast
+func timeToZero(value, rate float64) time.Duration {
+	if rate >= 0 {
+		return 0
+	}
+	seconds := value / -rate
+	if seconds > maxForecast.Seconds() {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// fitSlope returns the least-squares slope of values over times in units
+// per second, and false when the times don't spread
+func fitSlope(times []time.Time, values []float64) (float64, bool) {
+	if len(times) < 2 {
+		return 0, false
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	n := float64(len(times))
+	for i, at := range times {
+		x := at.Sub(times[0]).Seconds()
+		sumX += x
+		sumY += values[i]
+		sumXY += x * values[i]
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, false
+	}
+	return (n*sumXY - sumX*sumY) / denominator, true
+}
+
+// forecastLine describes the forecast for the memory panel
+func forecastLine(f MemoryForecast) string {
+	if !f.Ready {
+		return "(collecting samples)"
+	}
+	if f.AvailableETA == 0 && f.ExhaustETA == 0 {
+		return fmt.Sprintf("available %s/h, not running out", formatSignedBytes(f.AvailableRate*3600))
+	}
+
+	line := fmt.Sprintf("available %s/h", formatSignedBytes(f.AvailableRate*3600))
+	if f.AvailableETA > 0 {
+		line += ", exhausted in " + formatDuration(f.AvailableETA)
+	}
+	if f.ExhaustETA > 0 && f.ExhaustETA != f.AvailableETA {
+		line += ", with swap in " + formatDuration(f.ExhaustETA)
+	}
+	switch {
+	case f.Alert:
+		return redStyle.Render(line)
+	case f.ExhaustETA > 0 && f.ExhaustETA <= 4*f.AlertWithin:
+		return yellowStyle.Render(line)
+	}
+	return line
+}
+
+// formatSignedBytes formats a byte rate with its sign, e.g. "-1.5G"
+func formatSignedBytes(b float64) string {
+	if b < 0 {
+		return "-" + formatBytes(-b)
+	}
+	return "+" + formatBytes(b)
+}
+
+// readOOMScore reads <root>/<pid>/oom_score, the badness the OOM killer
+// ranks processes by, or -1 when it can't be read
+func readOOMScore(root string, pid int32) int {
+	score, err := strconv.Atoi(readSysfsString(filepath.Join(root, strconv.Itoa(int(pid)), "oom_score")))
+	if err != nil {
+		return -1
+	}
+	return score
+}
+
+// oomCandidates returns up to n processes in the order the OOM killer would
+// pick them
+func oomCandidates(procs []ProcessInfo, n int) []ProcessInfo {
+	var candidates []ProcessInfo
+	for _, p := range procs {
+		if p.OOMScore > 0 {
+			candidates = append(candidates, p)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].OOMScore > candidates[j].OOMScore
+	})
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+// oomLine lists the processes the OOM killer would kill first
+func oomLine(procs []ProcessInfo) string {
+	candidates := oomCandidates(procs, 3)
+	if len(candidates) == 0 {
+		return ""
+	}
+	var names []string
+	for _, p := range candidates {
+		names = append(names, fmt.Sprintf("%s (%d, score %d)", filepath.Base(p.Command), p.PID, p.OOMScore))
+	}
+	return "killed first: " + strings.Join(names, ", ")
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryForecasterUpdate(t *testing.T) {
+	f := &memoryForecaster{window: 10 * time.Minute, alertWithin: time.Hour}
+	start := time.Unix(100000, 0)
+
+	// Available memory falls by 1G per minute with 4G of free swap; swap
+	// stays untouched
+	var forecast MemoryForecast
+	for i := 0; i <= 6; i++ {
+		d := MemoryDetail{Available: uint64(20-i) << 30, SwapTotal: 8 << 30, SwapUsed: 4 << 30}
+		forecast = f.update(d, start.Add(time.Duration(i)*time.Minute))
+		if i < 4 && forecast.Ready {
+			t.Fatalf("forecast ready after %d minutes; expected a third of the window", i)
+		}
+	}
+
+	if !forecast.Ready || forecast.AvailableRate > -(1<<30)/60+1 || forecast.AvailableRate < -(1<<30)/60-1 {
+		t.Fatalf("forecast = %+v; expected available to fall 1G/min", forecast)
+	}
+	// 14G left at 1G/min, 18G including swap
+	if forecast.AvailableETA != 14*time.Minute || forecast.ExhaustETA != 18*time.Minute || !forecast.Alert {
+		t.Errorf("ETAs %v and %v, alert %v; expected 14m and 18m with an alert",
+			forecast.AvailableETA, forecast.ExhaustETA, forecast.Alert)
+	}
+	if got := stripAnsiCodes(forecastLine(forecast)); got != "available -60.0G/h, exhausted in 14m00s, with swap in 18m00s" {
+		t.Errorf("forecastLine = %q", got)
+	}
+
+	// Memory recovers: samples older than the window drop out
+	for i := 7; i <= 30; i++ {
+		forecast = f.update(MemoryDetail{Available: 20 << 30}, start.Add(time.Duration(i)*time.Minute))
+	}
+	if forecast.AvailableETA != 0 || forecast.ExhaustETA != 0 || forecast.Alert {
+		t.Errorf("flat memory forecast = %+v; expected no ETA", forecast)
+	}
+	if got := forecastLine(forecast); got != "available +0B/h, not running out" {
+		t.Errorf("forecastLine = %q; expected no exhaustion", got)
+	}
+}
+
+func TestTimeToZero(t *testing.T) {
+	tests := []struct {
+		value, rate float64
+		want        time.Duration
+	}{
+		{16 << 30, -(16 << 30) / 3600.0, time.Hour},
+		{16 << 30, 0, 0},
+		{16 << 30, 1024, 0},
+		// A near-flat fit on an idle machine would overflow a Duration
+		{16 << 30, -0.5, 0},
+		{16 << 30, -1e-9, 0},
+	}
+	for _, tt := range tests {
+		if got := timeToZero(tt.value, tt.rate); got != tt.want {
+			t.Errorf("timeToZero(%v, %v) = %v; expected %v", tt.value, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestForecastConfigValidate(t *testing.T) {
+	if err := defaultForecastConfig().validate(); err != nil {
+		t.Errorf("default forecast config is invalid: %v", err)
+	}
+	for _, c := range []forecastConfig{{Window: "15m", AlertWithin: "never"}, {Window: "0s", AlertWithin: "1h"}} {
+		if err := c.validate(); err == nil {
+			t.Errorf("%+v passed validation", c)
+		}
+	}
+}
+
+func TestOOMCandidates(t *testing.T) {
+	root := filepath.Join("testdata", "proc")
+	if got := readOOMScore(root, 100); got != 812 {
+		t.Errorf("readOOMScore(100) = %d; expected 812", got)
+	}
+	if got := readOOMScore(root, 300); got != -1 {
+		t.Errorf("readOOMScore without a file = %d; expected -1", got)
+	}
+
+	procs := []ProcessInfo{
+		{PID: 1, Command: "/sbin/init", OOMScore: 0},
+		{PID: 200, Command: "/usr/bin/envoy", OOMScore: 3},
+		{PID: 100, Command: "/usr/sbin/nginx", OOMScore: 812},
+		{PID: 300, Command: "/usr/bin/java", OOMScore: 640},
+		{PID: 400, Command: "/usr/bin/cat", OOMScore: 1},
+	}
+	want := "killed first: nginx (100, score 812), java (300, score 640), envoy (200, score 3)"
+	if got := oomLine(procs); got != want {
+		t.Errorf("oomLine = %q; expected %q", got, want)
+	}
+}
+
+func TestViewMemoryForecastAlert(t *testing.T) {
+	m := model{
+		width:  100,
+		height: 12,
+		stats: SystemStats{
+			CPUCores: []float64{10},
+			MemoryForecast: MemoryForecast{Ready: true, Alert: true, AvailableRate: -(2 << 30) / 3600.0,
+				AvailableETA: 20 * time.Minute, ExhaustETA: 25 * time.Minute, AlertWithin: time.Hour},
+		},
+	}
+	for i := 0; i < 20; i++ {
+		m.stats.Processes = append(m.stats.Processes, ProcessInfo{PID: int32(1000 + i), Command: "/bin/worker"})
+	}
+
+	view := stripAnsiCodes(m.View())
+	lines := strings.Split(strings.TrimSuffix(view, "\n"), "\n")
+	if !strings.Contains(lines[0], "memory forecast: available memory and swap run out in 25m00s at -2.0G/h") {
+		t.Errorf("first line is not the alert banner: %q", lines[0])
+	}
+	if len(lines) > m.height {
+		t.Errorf("view with a banner has %d lines; expected at most %d", len(lines), m.height)
+	}
+}
@@ -0,0 +1,573 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GPU vendor type
+type gpuVendor int
+
+const (
+	gpuVendorNone gpuVendor = iota
+	gpuVendorNVIDIA
+	gpuVendorAMD
+	gpuVendorSysfs
+)
+
+// Names accepted by --gpu and the "gpu" config option
+var gpuVendorNames = map[string]gpuVendor{
+	"nvidia": gpuVendorNVIDIA,
+	"amd":    gpuVendorAMD,
+	"sysfs":  gpuVendorSysfs,
+}
+
+// gpuMode is a parsed GPU backend selection. In auto mode the backends are
+// probed at startup and re-probed periodically while none is found.
+type gpuMode struct {
+	auto    bool
+	vendors []gpuVendor
+}
+
+// How often auto mode looks for a GPU again when none was found, so a driver
+// loaded after startup or a hot-plugged eGPU gets picked up
+const gpuReprobeInterval = 30 * time.Second
+
+// Active GPU backends; guarded by gpuMu since stats are collected from
+// tea.Cmd goroutines
+var (
+	gpuMu            sync.Mutex
+	gpuSelection     = gpuMode{auto: true}
+	activeGPUVendors []gpuVendor
+	lastGPUProbe     time.Time
+)
+
+// commandRunner runs an external command and returns its standard output.
+// The GPU backends go through this interface so their parsers can be
+// exercised against captured output on machines without the vendor tools.
+type commandRunner interface {
+	Output(name string, args ...string) ([]byte, error)
+}
+
+// execRunner is the commandRunner used in production; it shells out via os/exec
+type execRunner struct{}
+
+func (execRunner) Output(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+// runner is the commandRunner used by all GPU functions and the systemctl
+// query of the services view
+var runner commandRunner = execRunner{}
+
+// Command lines used to query the vendor tools
+var (
+	nvidiaUsageArgs  = []string{"--query-gpu=utilization.gpu", "--format=csv,noheader,nounits"}
+	nvidiaMemoryArgs = []string{"--query-gpu=memory.used,memory.total", "--format=csv,noheader,nounits"}
+	rocmUsageArgs    = []string{"--showuse"}
+	rocmMemoryArgs   = []string{"--showmeminfo", "vram"}
+	nvidiaPowerArgs  = []string{"--query-gpu=power.draw", "--format=csv,noheader,nounits"}
+	rocmPowerArgs    = []string{"--showpower"}
+	nvidiaDeviceArgs = []string{"--query-gpu=utilization.gpu,memory.used,memory.total,temperature.gpu", "--format=csv,noheader,nounits"}
+	rocmDeviceArgs   = []string{"--showuse", "--showmeminfo", "vram", "--showtemp"}
+)
+
+// parseGPUMode parses "auto", "none", or a comma-separated list of backends
+func parseGPUMode(s string) (gpuMode, error) {
+	switch strings.TrimSpace(s) {
+	case "", "auto":
+		return gpuMode{auto: true}, nil
+	case "none":
+		return gpuMode{}, nil
+	}
+
+	var mode gpuMode
+	for _, name := range strings.Split(s, ",") {
+		vendor, ok := gpuVendorNames[strings.TrimSpace(name)]
+		if !ok {
+			return gpuMode{}, fmt.Errorf("unknown GPU backend %q (expected nvidia, amd, sysfs, none or auto)", strings.TrimSpace(name))
+		}
+		if !containsVendor(mode.vendors, vendor) {
+			mode.vendors = append(mode.vendors, vendor)
+		}
+	}
+	return mode, nil
+}
+
+// configureGPUs applies a backend selection, probing immediately in auto mode
+func configureGPUs(mode gpuMode) {
+	gpuMu.Lock()
+	defer gpuMu.Unlock()
+
+	gpuSelection = mode
+	if mode.auto {
+		activeGPUVendors = probeGPUVendors(runner, sysfsRoot)
+		lastGPUProbe = time.Now()
+	} else {
+		activeGPUVendors = mode.vendors
+	}
+}
+
+// refreshGPUBackends re-probes in auto mode when no backend is active and
+// gpuReprobeInterval has passed since the last probe
+func refreshGPUBackends(now time.Time) {
+	gpuMu.Lock()
+	defer gpuMu.Unlock()
+
+	if !gpuSelection.auto || len(activeGPUVendors) > 0 || now.Sub(lastGPUProbe) < gpuReprobeInterval {
+		return
+	}
+	activeGPUVendors = probeGPUVendors(runner, sysfsRoot)
+	lastGPUProbe = now
+}
+
+// gpuVendors returns a snapshot of the active GPU backends
+func gpuVendors() []gpuVendor {
+	gpuMu.Lock()
+	defer gpuMu.Unlock()
+	return activeGPUVendors
+}
+
+// probeGPUVendors returns every backend that answers successfully using r.
+// The sysfs backend is only enabled for cards not already covered by a
+// vendor tool, so amdgpu cards are not counted twice when rocm-smi works.
+func probeGPUVendors(r commandRunner, root string) []gpuVendor {
+	var vendors []gpuVendor
+
+	if _, err := r.Output("nvidia-smi", nvidiaUsageArgs...); err == nil {
+		vendors = append(vendors, gpuVendorNVIDIA)
+	}
+
+	if _, err := r.Output("rocm-smi", rocmUsageArgs...); err == nil {
+		vendors = append(vendors, gpuVendorAMD)
+	}
+
+	// Try reading the DRM driver attributes directly
+	if len(sysfsGPUsFor(root, vendors)) > 0 {
+		vendors = append(vendors, gpuVendorSysfs)
+	}
+
+	return vendors
+}
+
+func containsVendor(vendors []gpuVendor, vendor gpuVendor) bool {
+	for _, v := range vendors {
+		if v == vendor {
+			return true
+		}
+	}
+	return false
+}
+
+// getGPUUsage returns the mean utilization over all GPUs of all active backends
+func getGPUUsage() float64 {
+	vendors := gpuVendors()
+
+	var usages []float64
+	for _, vendor := range vendors {
+		switch vendor {
+		case gpuVendorNVIDIA:
+			usages = append(usages, getGPUUsageNVIDIA()...)
+		case gpuVendorAMD:
+			usages = append(usages, getGPUUsageAMD()...)
+		case gpuVendorSysfs:
+			usages = append(usages, getGPUUsageSysfs(vendors)...)
+		}
+	}
+	return average(usages)
+}
+
+func getGPUUsageNVIDIA() []float64 {
+	output, err := runner.Output("nvidia-smi", nvidiaUsageArgs...)
+	if err != nil {
+		return nil
+	}
+
+	return parseNVIDIAUsage(output)
+}
+
+// parseNVIDIAUsage parses `nvidia-smi --query-gpu=utilization.gpu` CSV output
+// into one utilization value per GPU. GPUs reporting "[N/A]" or
+// "[Not Supported]" are skipped.
+func parseNVIDIAUsage(output []byte) []float64 {
+	var usages []float64
+	for _, line := range strings.Split(string(output), "\n") {
+		usage, err := strconv.ParseFloat(strings.TrimSpace(line), 64)
+		if err != nil {
+			continue
+		}
+		usages = append(usages, usage)
+	}
+	return usages
+}
+
+func getGPUUsageAMD() []float64 {
+	output, err := runner.Output("rocm-smi", rocmUsageArgs...)
+	if err != nil {
+		return nil
+	}
+
+	return parseROCmUsage(output)
+}
+
+// parseROCmUsage parses `rocm-smi --showuse` output into one utilization value per GPU
+func parseROCmUsage(output []byte) []float64 {
+	// rocm-smi --showuse output format:
+	// ========================= ROCm System Management Interface =========================
+	// ================================ GPU use ================================
+	// GPU[0]		: GPU use (%): 25
+	var usages []float64
+	for _, line := range strings.Split(string(output), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "GPU[") || !strings.Contains(line, "GPU use (%)") {
+			continue
+		}
+		// Extract value after the last colon
+		if valueStr, ok := extractValueAfterLastColon(line); ok {
+			if usage, err := strconv.ParseFloat(valueStr, 64); err == nil {
+				usages = append(usages, usage)
+			}
+		}
+	}
+	return usages
+}
+
+// extractValueAfterLastColon extracts and trims the string after the last colon in a line
+func extractValueAfterLastColon(line string) (string, bool) {
+	lastColonIdx := strings.LastIndex(line, ":")
+	if lastColonIdx == -1 || lastColonIdx+1 > len(line) {
+		return "", false
+	}
+	return strings.TrimSpace(line[lastColonIdx+1:]), true
+}
+
+// average returns the arithmetic mean of values, or 0 for an empty slice
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0.0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// getGPUMemory returns used memory as a percentage of total memory summed
+// over all GPUs of all active backends
+func getGPUMemory() float64 {
+	vendors := gpuVendors()
+
+	var used, total float64
+	for _, vendor := range vendors {
+		var u, t float64
+		switch vendor {
+		case gpuVendorNVIDIA:
+			u, t = getGPUMemoryNVIDIA()
+		case gpuVendorAMD:
+			u, t = getGPUMemoryAMD()
+		case gpuVendorSysfs:
+			u, t = getGPUMemorySysfs(vendors)
+		}
+		used += u
+		total += t
+	}
+
+	if total == 0 {
+		return 0.0
+	}
+	return (used / total) * 100.0
+}
+
+// getGPUTemp returns the hottest GPU temperature in degrees Celsius, or 0
+// when no active backend reports one
+func getGPUTemp() float64 {
+	vendors := gpuVendors()
+	if containsVendor(vendors, gpuVendorSysfs) {
+		return getGPUTempSysfs(vendors)
+	}
+	return 0.0
+}
+
+func getGPUMemoryNVIDIA() (used, total float64) {
+	output, err := runner.Output("nvidia-smi", nvidiaMemoryArgs...)
+	if err != nil {
+		return 0, 0
+	}
+
+	return parseNVIDIAMemory(output)
+}
+
+// parseNVIDIAMemory parses `nvidia-smi --query-gpu=memory.used,memory.total`
+// CSV output and returns used and total memory in bytes summed over all GPUs
+// that report both values. nvidia-smi reports MiB, while the other backends
+// report bytes.
+func parseNVIDIAMemory(output []byte) (used, total float64) {
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Split(strings.TrimSpace(line), ",")
+		if len(parts) != 2 {
+			continue
+		}
+
+		u, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		t, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err1 != nil || err2 != nil || t == 0 {
+			continue
+		}
+		used += u * (1 << 20)
+		total += t * (1 << 20)
+	}
+	return used, total
+}
+
+func getGPUMemoryAMD() (used, total float64) {
+	output, err := runner.Output("rocm-smi", rocmMemoryArgs...)
+	if err != nil {
+		return 0, 0
+	}
+
+	return parseROCmMemory(output)
+}
+
+// parseROCmMemory parses `rocm-smi --showmeminfo vram` output and returns used
+// and total VRAM summed over all GPUs that report both values.
+func parseROCmMemory(output []byte) (used, total float64) {
+	// rocm-smi --showmeminfo vram output format:
+	// ========================= ROCm System Management Interface =========================
+	// ================================ VRAM Total Memory (B) ================================
+	// GPU[0]		: VRAM Total Memory (B): 17163091968
+	// ================================ VRAM Total Used Memory (B) ================================
+	// GPU[0]		: VRAM Total Used Memory (B): 1234567890
+	totals := make(map[string]float64)
+	useds := make(map[string]float64)
+	var order []string
+
+	for _, line := range strings.Split(string(output), "\n") {
+		trimmedLine := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmedLine, "GPU[") {
+			continue
+		}
+		id := trimmedLine[:strings.Index(trimmedLine, "]")+1]
+
+		valueStr, ok := extractValueAfterLastColon(line)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			continue
+		}
+
+		if strings.Contains(line, "VRAM Total Used Memory (B)") {
+			useds[id] = value
+		} else if strings.Contains(line, "VRAM Total Memory (B)") {
+			if _, seen := totals[id]; !seen {
+				order = append(order, id)
+			}
+			totals[id] = value
+		}
+	}
+
+	// Only count GPUs where we successfully parsed both values
+	for _, id := range order {
+		u, ok := useds[id]
+		if !ok || totals[id] == 0 {
+			continue
+		}
+		used += u
+		total += totals[id]
+	}
+	return used, total
+}
+
+// getGPUPower returns the power draw in watts summed over all GPUs of all
+// active backends, and whether any GPU reported one
+func getGPUPower() (float64, bool) {
+	vendors := gpuVendors()
+
+	var watts []float64
+	for _, vendor := range vendors {
+		switch vendor {
+		case gpuVendorNVIDIA:
+			watts = append(watts, getGPUPowerNVIDIA()...)
+		case gpuVendorAMD:
+			watts = append(watts, getGPUPowerAMD()...)
+		case gpuVendorSysfs:
+			watts = append(watts, getGPUPowerSysfs(vendors)...)
+		}
+	}
+
+	var total float64
+	for _, w := range watts {
+		total += w
+	}
+	return total, len(watts) > 0
+}
+
+func getGPUPowerNVIDIA() []float64 {
+	output, err := runner.Output("nvidia-smi", nvidiaPowerArgs...)
+	if err != nil {
+		return nil
+	}
+
+	return parseNVIDIAPower(output)
+}
+
+// parseNVIDIAPower parses `nvidia-smi --query-gpu=power.draw` CSV output into
+// one value in watts per GPU. The output has the same one-number-per-line
+// shape as the utilization query, including "[N/A]" for GPUs without a
+// power sensor.
+func parseNVIDIAPower(output []byte) []float64 {
+	return parseNVIDIAUsage(output)
+}
+
+func getGPUPowerAMD() []float64 {
+	output, err := runner.Output("rocm-smi", rocmPowerArgs...)
+	if err != nil {
+		return nil
+	}
+
+	return parseROCmPower(output)
+}
+
+// parseROCmPower parses `rocm-smi --showpower` output into one value in watts
+// per GPU. ROCm 5 reports "Average Graphics Package Power (W)" and ROCm 6
+// "Current Socket Graphics Package Power (W)".
+func parseROCmPower(output []byte) []float64 {
+	var watts []float64
+	for _, line := range strings.Split(string(output), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "GPU[") || !strings.Contains(line, "Power (W)") {
+			continue
+		}
+		if valueStr, ok := extractValueAfterLastColon(line); ok {
+			if w, err := strconv.ParseFloat(valueStr, 64); err == nil {
+				watts = append(watts, w)
+			}
+		}
+	}
+	return watts
+}
+
+// GPUDevice is the utilization, memory use (percent) and temperature (°C) of
+// one GPU; the Has* flags are false for values its backend doesn't report
+type GPUDevice struct {
+	Usage     float64
+	Memory    float64
+	Temp      float64
+	HasUsage  bool
+	HasMemory bool
+	HasTemp   bool
+}
+
+// getGPUDevices returns every GPU of the active backends, in the order of
+// getGPUUsage, so an index names the same GPU from sample to sample
+func getGPUDevices() []GPUDevice {
+	vendors := gpuVendors()
+
+	var devices []GPUDevice
+	for _, vendor := range vendors {
+		switch vendor {
+		case gpuVendorNVIDIA:
+			if output, err := runner.Output("nvidia-smi", nvidiaDeviceArgs...); err == nil {
+				devices = append(devices, parseNVIDIADevices(output)...)
+			}
+		case gpuVendorAMD:
+			if output, err := runner.Output("rocm-smi", rocmDeviceArgs...); err == nil {
+				devices = append(devices, parseROCmDevices(output)...)
+			}
+		case gpuVendorSysfs:
+			for _, gpu := range sysfsGPUsFor(sysfsRoot, vendors) {
+				d := GPUDevice{Usage: gpu.Busy, HasUsage: gpu.HasBusy, Temp: gpu.TempC, HasTemp: gpu.HasTemp}
+				if gpu.VRAMTotal > 0 {
+					d.Memory, d.HasMemory = gpu.VRAMUsed/gpu.VRAMTotal*100, true
+				}
+				devices = append(devices, d)
+			}
+		}
+	}
+	return devices
+}
+
+// parseNVIDIADevices parses `nvidia-smi --query-gpu=utilization.gpu,
+// memory.used,memory.total,temperature.gpu` CSV output into one device per
+// line, leaving out the "[N/A]" values
+func parseNVIDIADevices(output []byte) []GPUDevice {
+	var devices []GPUDevice
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Split(strings.TrimSpace(line), ",")
+		if len(parts) != 4 {
+			continue
+		}
+		values := make([]float64, len(parts))
+		ok := make([]bool, len(parts))
+		for i, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			values[i], ok[i] = v, err == nil
+		}
+
+		d := GPUDevice{Usage: values[0], HasUsage: ok[0], Temp: values[3], HasTemp: ok[3]}
+		// Used and total are both in MiB, so their ratio needs no conversion
+		if ok[1] && ok[2] && values[2] > 0 {
+			d.Memory, d.HasMemory = values[1]/values[2]*100, true
+		}
+		devices = append(devices, d)
+	}
+	return devices
+}
+
+// parseROCmDevices parses `rocm-smi --showuse --showmeminfo vram --showtemp`
+// output into one device per GPU[n], taking the edge temperature that the
+// amdgpu hwmon also reports first
+func parseROCmDevices(output []byte) []GPUDevice {
+	devices := make(map[string]*GPUDevice)
+	used := make(map[string]float64)
+	var order []string
+
+	for _, line := range strings.Split(string(output), "\n") {
+		trimmedLine := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmedLine, "GPU[") {
+			continue
+		}
+		id := trimmedLine[:strings.Index(trimmedLine, "]")+1]
+		valueStr, ok := extractValueAfterLastColon(line)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			continue
+		}
+
+		d := devices[id]
+		if d == nil {
+			d = &GPUDevice{}
+			devices[id] = d
+			order = append(order, id)
+		}
+		switch {
+		case strings.Contains(line, "GPU use (%)"):
+			d.Usage, d.HasUsage = value, true
+		case strings.Contains(line, "VRAM Total Used Memory (B)"):
+			used[id] = value
+		case strings.Contains(line, "VRAM Total Memory (B)"):
+			d.Memory = value
+		case strings.Contains(line, "Temperature") && !d.HasTemp:
+			d.Temp, d.HasTemp = value, true
+		}
+	}
+
+	result := make([]GPUDevice, 0, len(order))
+	for _, id := range order {
+		d := devices[id]
+		total := d.Memory
+		d.Memory = 0
+		if u, ok := used[id]; ok && total > 0 {
+			d.Memory, d.HasMemory = u/total*100, true
+		}
+		result = append(result, *d)
+	}
+	return result
+}
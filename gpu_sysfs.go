@@ -0,0 +1,172 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kernel drivers the sysfs GPU backend understands
+var sysfsGPUDrivers = map[string]bool{
+	"amdgpu": true,
+	"i915":   true,
+	"xe":     true,
+}
+
+// sysfsGPU holds the values read for one DRM card. Fields the driver does not
+// expose are left at zero and flagged by the Has* booleans.
+type sysfsGPU struct {
+	Card      string
+	Driver    string
+	Busy      float64
+	HasBusy   bool
+	VRAMUsed  float64
+	VRAMTotal float64
+	TempC     float64
+	HasTemp   bool
+	PowerW    float64
+	HasPower  bool
+}
+
+// readSysfsGPUs reads every supported card under <root>/class/drm. Connector
+// entries such as card0-DP-1 are skipped.
+func readSysfsGPUs(root string) []sysfsGPU {
+	cards, _ := filepath.Glob(filepath.Join(root, "class", "drm", "card*"))
+	sort.Strings(cards)
+
+	var gpus []sysfsGPU
+	for _, card := range cards {
+		name := filepath.Base(card)
+		if strings.Contains(name, "-") {
+			continue
+		}
+
+		device := filepath.Join(card, "device")
+		driver := readUeventDriver(filepath.Join(device, "uevent"))
+		if !sysfsGPUDrivers[driver] {
+			continue
+		}
+
+		gpu := sysfsGPU{Card: name, Driver: driver}
+		if busy, err := readSysfsFloat(filepath.Join(device, "gpu_busy_percent")); err == nil {
+			gpu.Busy = busy
+			gpu.HasBusy = true
+		}
+
+		used, errUsed := readSysfsFloat(filepath.Join(device, "mem_info_vram_used"))
+		total, errTotal := readSysfsFloat(filepath.Join(device, "mem_info_vram_total"))
+		if errUsed == nil && errTotal == nil && total > 0 {
+			gpu.VRAMUsed = used
+			gpu.VRAMTotal = total
+		}
+
+		// hwmon reports temperatures in millidegrees Celsius
+		temps, _ := filepath.Glob(filepath.Join(device, "hwmon", "hwmon*", "temp1_input"))
+		if len(temps) > 0 {
+			if milli, err := readSysfsFloat(temps[0]); err == nil {
+				gpu.TempC = milli / 1000.0
+				gpu.HasTemp = true
+			}
+		}
+
+		// Power is in microwatts; amdgpu exposes power1_average, i915/xe
+		// and newer amdgpu power1_input
+		for _, name := range []string{"power1_average", "power1_input"} {
+			paths, _ := filepath.Glob(filepath.Join(device, "hwmon", "hwmon*", name))
+			if len(paths) == 0 {
+				continue
+			}
+			if micro, err := readSysfsFloat(paths[0]); err == nil {
+				gpu.PowerW = micro / 1e6
+				gpu.HasPower = true
+				break
+			}
+		}
+
+		gpus = append(gpus, gpu)
+	}
+
+	return gpus
+}
+
+// readUeventDriver returns the DRIVER= value from a sysfs uevent file
+func readUeventDriver(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if driver, ok := strings.CutPrefix(line, "DRIVER="); ok {
+			return strings.TrimSpace(driver)
+		}
+	}
+	return ""
+}
+
+// readSysfsFloat reads a sysfs attribute holding a single number
+func readSysfsFloat(path string) (float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+}
+
+// sysfsGPUsFor returns the sysfs cards not already covered by the other
+// active backends; amdgpu cards are left to rocm-smi when it is active.
+func sysfsGPUsFor(root string, vendors []gpuVendor) []sysfsGPU {
+	gpus := readSysfsGPUs(root)
+	if !containsVendor(vendors, gpuVendorAMD) {
+		return gpus
+	}
+
+	var filtered []sysfsGPU
+	for _, gpu := range gpus {
+		if gpu.Driver != "amdgpu" {
+			filtered = append(filtered, gpu)
+		}
+	}
+	return filtered
+}
+
+func getGPUUsageSysfs(vendors []gpuVendor) []float64 {
+	var busy []float64
+	for _, gpu := range sysfsGPUsFor(sysfsRoot, vendors) {
+		if gpu.HasBusy {
+			busy = append(busy, gpu.Busy)
+		}
+	}
+	return busy
+}
+
+func getGPUMemorySysfs(vendors []gpuVendor) (used, total float64) {
+	for _, gpu := range sysfsGPUsFor(sysfsRoot, vendors) {
+		used += gpu.VRAMUsed
+		total += gpu.VRAMTotal
+	}
+	return used, total
+}
+
+// getGPUTempSysfs returns the hottest card temperature, or 0 if no card
+// exposes a hwmon temperature
+func getGPUTempSysfs(vendors []gpuVendor) float64 {
+	var hottest float64
+	for _, gpu := range sysfsGPUsFor(sysfsRoot, vendors) {
+		if gpu.HasTemp && gpu.TempC > hottest {
+			hottest = gpu.TempC
+		}
+	}
+	return hottest
+}
+
+func getGPUPowerSysfs(vendors []gpuVendor) []float64 {
+	var watts []float64
+	for _, gpu := range sysfsGPUsFor(sysfsRoot, vendors) {
+		if gpu.HasPower {
+			watts = append(watts, gpu.PowerW)
+		}
+	}
+	return watts
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSysfsGPUs(t *testing.T) {
+	gpus := readSysfsGPUs(filepath.Join("testdata", "sysfs", "gpu"))
+
+	// card3 (nouveau) and the connector/render nodes must be ignored
+	if len(gpus) != 3 {
+		t.Fatalf("readSysfsGPUs() returned %d cards; expected 3: %+v", len(gpus), gpus)
+	}
+
+	amd := gpus[0]
+	if amd.Card != "card0" || amd.Driver != "amdgpu" {
+		t.Errorf("gpus[0] = %s/%s; expected card0/amdgpu", amd.Card, amd.Driver)
+	}
+	if !amd.HasBusy || amd.Busy != 30 {
+		t.Errorf("card0 busy = %v (has=%v); expected 30", amd.Busy, amd.HasBusy)
+	}
+	if amd.VRAMUsed != 4294967296 || amd.VRAMTotal != 17163091968 {
+		t.Errorf("card0 VRAM = %v/%v; expected 4294967296/17163091968", amd.VRAMUsed, amd.VRAMTotal)
+	}
+	if !amd.HasTemp || amd.TempC != 52 {
+		t.Errorf("card0 temp = %v (has=%v); expected 52", amd.TempC, amd.HasTemp)
+	}
+	if !amd.HasPower || amd.PowerW != 85 {
+		t.Errorf("card0 power = %v (has=%v); expected 85", amd.PowerW, amd.HasPower)
+	}
+
+	intel := gpus[1]
+	if intel.Driver != "i915" || intel.HasBusy || intel.VRAMTotal != 0 || intel.HasTemp {
+		t.Errorf("gpus[1] = %+v; expected an i915 card without busy, VRAM or temperature", intel)
+	}
+}
+
+func TestSysfsGPUBackend(t *testing.T) {
+	origRoot, origVendors := sysfsRoot, activeGPUVendors
+	defer func() {
+		sysfsRoot, activeGPUVendors = origRoot, origVendors
+	}()
+
+	sysfsRoot = filepath.Join("testdata", "sysfs", "gpu")
+	vendors := probeGPUVendors(fixtureRunner{dir: t.TempDir()}, sysfsRoot)
+	if len(vendors) != 1 || vendors[0] != gpuVendorSysfs {
+		t.Fatalf("probeGPUVendors() = %v; expected [sysfs]", vendors)
+	}
+	activeGPUVendors = vendors
+
+	// Only the two amdgpu cards report busy percent
+	if usage := getGPUUsage(); usage != 60 {
+		t.Errorf("getGPUUsage() = %.2f; expected 60", usage)
+	}
+	wantMemory := (4294967296.0 + 8589934592.0) / (2 * 17163091968.0) * 100
+	if memory := getGPUMemory(); math.Abs(memory-wantMemory) > 0.01 {
+		t.Errorf("getGPUMemory() = %.2f; expected %.2f", memory, wantMemory)
+	}
+	if temp := getGPUTemp(); temp != 71.5 {
+		t.Errorf("getGPUTemp() = %.1f; expected 71.5", temp)
+	}
+	if power, ok := getGPUPower(); !ok || power != 85 {
+		t.Errorf("getGPUPower() = %.1f, %v; expected 85", power, ok)
+	}
+}
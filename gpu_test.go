@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixtureRunner is a commandRunner that replays captured vendor tool output
+// from a directory under testdata/gpu. A command's output is read from
+// "<base>.txt" when the command succeeded and "<base>.err" when it exited
+// with a non-zero status; a missing fixture means the tool is not installed.
+type fixtureRunner struct {
+	dir string
+}
+
+var fixtureBases = map[string]string{
+	"nvidia-smi " + strings.Join(nvidiaUsageArgs, " "):  "nvidia-usage",
+	"nvidia-smi " + strings.Join(nvidiaMemoryArgs, " "): "nvidia-memory",
+	"rocm-smi " + strings.Join(rocmUsageArgs, " "):      "rocm-usage",
+	"rocm-smi " + strings.Join(rocmMemoryArgs, " "):     "rocm-memory",
+	"nvidia-smi " + strings.Join(nvidiaPowerArgs, " "):  "nvidia-power",
+	"rocm-smi " + strings.Join(rocmPowerArgs, " "):      "rocm-power",
+	"nvidia-smi " + strings.Join(nvidiaDeviceArgs, " "): "nvidia-devices",
+	"rocm-smi " + strings.Join(rocmDeviceArgs, " "):     "rocm-devices",
+}
+
+func (f fixtureRunner) Output(name string, args ...string) ([]byte, error) {
+	base, ok := fixtureBases[name+" "+strings.Join(args, " ")]
+	if !ok {
+		return nil, errors.New("unexpected command: " + name + " " + strings.Join(args, " "))
+	}
+
+	if out, err := os.ReadFile(filepath.Join(f.dir, base+".txt")); err == nil {
+		return out, nil
+	}
+	if out, err := os.ReadFile(filepath.Join(f.dir, base+".err")); err == nil {
+		return out, errors.New("exit status 1")
+	}
+	return nil, errors.New("executable file not found in $PATH")
+}
+
+func TestGPUFixtures(t *testing.T) {
+	tests := []struct {
+		dir         string
+		wantVendors []gpuVendor
+		wantUsage   float64
+		wantMemory  float64
+		wantPower   float64
+	}{
+		{"nvidia-535-single", []gpuVendor{gpuVendorNVIDIA}, 45, 6144.0 / 24564.0 * 100, 71.23},
+		{"nvidia-550-multi", []gpuVendor{gpuVendorNVIDIA}, 38, 123001.0 / (4 * 81559.0) * 100, 825.6},
+		{"nvidia-470-na", []gpuVendor{gpuVendorNVIDIA}, 30, 25, 0},
+		{"nvidia-390-not-supported", []gpuVendor{gpuVendorNVIDIA}, 0, 12.5, 0},
+		{"nvidia-driver-mismatch", nil, 0, 0, 0},
+		{"nvidia-no-driver", nil, 0, 0, 0},
+		{"rocm-5.4-single", []gpuVendor{gpuVendorAMD}, 25, 4290772992.0 / 17163091968.0 * 100, 35},
+		{"rocm-6.0-multi", []gpuVendor{gpuVendorAMD}, 50, (10960896.0 + 68691738624.0) / (2 * 68702699520.0) * 100, 700.5},
+		{"rocm-warning-banner", []gpuVendor{gpuVendorAMD}, 60, 25, 0},
+		{"rocm-na", []gpuVendor{gpuVendorAMD}, 40, 25, 0},
+		{"rocm-no-devices", nil, 0, 0, 0},
+	}
+
+	origRunner, origVendors := runner, activeGPUVendors
+	defer func() {
+		runner, activeGPUVendors = origRunner, origVendors
+	}()
+
+	// An empty sysfs tree so the host's own cards don't influence detection
+	emptySysfs := t.TempDir()
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			runner = fixtureRunner{dir: filepath.Join("testdata", "gpu", tt.dir)}
+
+			vendors := probeGPUVendors(runner, emptySysfs)
+			if !reflect.DeepEqual(vendors, tt.wantVendors) {
+				t.Fatalf("probeGPUVendors() = %v; expected %v", vendors, tt.wantVendors)
+			}
+			activeGPUVendors = vendors
+
+			if usage := getGPUUsage(); math.Abs(usage-tt.wantUsage) > 0.01 {
+				t.Errorf("getGPUUsage() = %.2f; expected %.2f", usage, tt.wantUsage)
+			}
+			if memory := getGPUMemory(); math.Abs(memory-tt.wantMemory) > 0.01 {
+				t.Errorf("getGPUMemory() = %.2f; expected %.2f", memory, tt.wantMemory)
+			}
+			if power, ok := getGPUPower(); math.Abs(power-tt.wantPower) > 0.01 || ok != (tt.wantPower > 0) {
+				t.Errorf("getGPUPower() = %.2f, %v; expected %.2f", power, ok, tt.wantPower)
+			}
+		})
+	}
+}
+
+func TestGetGPUDevices(t *testing.T) {
+	origRunner, origVendors := runner, activeGPUVendors
+	defer func() {
+		runner, activeGPUVendors = origRunner, origVendors
+	}()
+
+	runner = fixtureRunner{dir: filepath.Join("testdata", "gpu", "nvidia-550-multi")}
+	activeGPUVendors = []gpuVendor{gpuVendorNVIDIA}
+	devices := getGPUDevices()
+	if len(devices) != 4 {
+		t.Fatalf("getGPUDevices() = %+v; expected 4 NVIDIA GPUs", devices)
+	}
+	if d := devices[1]; d.Usage != 100 || !d.HasTemp || d.Temp != 87 || math.Abs(d.Memory-81000.0/81559*100) > 0.01 {
+		t.Errorf("GPU 1 = %+v", d)
+	}
+	if devices[2].HasTemp || !devices[2].HasUsage {
+		t.Errorf("GPU 2 = %+v; expected usage without a temperature", devices[2])
+	}
+
+	runner = fixtureRunner{dir: filepath.Join("testdata", "gpu", "rocm-6.0-multi")}
+	activeGPUVendors = []gpuVendor{gpuVendorAMD}
+	want := []GPUDevice{
+		{Usage: 10, Memory: 10960896.0 / 68702699520 * 100, Temp: 38, HasUsage: true, HasMemory: true, HasTemp: true},
+		{Usage: 90, Memory: 68691738624.0 / 68702699520 * 100, Temp: 71, HasUsage: true, HasMemory: true, HasTemp: true},
+	}
+	if devices := getGPUDevices(); !reflect.DeepEqual(devices, want) {
+		t.Errorf("getGPUDevices() = %+v; expected %+v", devices, want)
+	}
+
+	// Without the combined query's fixture the tool counts as missing
+	runner = fixtureRunner{dir: filepath.Join("testdata", "gpu", "nvidia-535-single")}
+	activeGPUVendors = []gpuVendor{gpuVendorNVIDIA}
+	if devices := getGPUDevices(); len(devices) != 0 {
+		t.Errorf("getGPUDevices() without nvidia-smi = %+v; expected none", devices)
+	}
+}
+
+func TestGPUParsersIgnoreErrorBanners(t *testing.T) {
+	banner := []byte("Failed to initialize NVML: Driver/library version mismatch\n")
+	if usages := parseNVIDIAUsage(banner); len(usages) != 0 {
+		t.Errorf("parseNVIDIAUsage(banner) = %v; expected no values", usages)
+	}
+	if used, total := parseNVIDIAMemory(banner); used != 0 || total != 0 {
+		t.Errorf("parseNVIDIAMemory(banner) = %v, %v; expected 0, 0", used, total)
+	}
+
+	rocmBanner := []byte("ERROR: GPU[0]\t\t: Unable to get GPU use\n")
+	if usages := parseROCmUsage(rocmBanner); len(usages) != 0 {
+		t.Errorf("parseROCmUsage(banner) = %v; expected no values", usages)
+	}
+}
+
+func TestParseGPUMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    gpuMode
+		wantErr bool
+	}{
+		{input: "auto", want: gpuMode{auto: true}},
+		{input: "", want: gpuMode{auto: true}},
+		{input: "none", want: gpuMode{}},
+		{input: "nvidia", want: gpuMode{vendors: []gpuVendor{gpuVendorNVIDIA}}},
+		{input: "nvidia, sysfs,nvidia", want: gpuMode{vendors: []gpuVendor{gpuVendorNVIDIA, gpuVendorSysfs}}},
+		{input: "intel", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseGPUMode(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGPUMode(%q) error = %v; wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseGPUMode(%q) = %+v; expected %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMixedGPUBackends(t *testing.T) {
+	origRunner, origRoot, origVendors := runner, sysfsRoot, activeGPUVendors
+	defer func() {
+		runner, sysfsRoot, activeGPUVendors = origRunner, origRoot, origVendors
+	}()
+
+	// One NVIDIA GPU at 45% plus the sysfs fixture's two amdgpu cards at 30% and 90%
+	runner = fixtureRunner{dir: filepath.Join("testdata", "gpu", "nvidia-535-single")}
+	sysfsRoot = filepath.Join("testdata", "sysfs", "gpu")
+
+	activeGPUVendors = probeGPUVendors(runner, sysfsRoot)
+	want := []gpuVendor{gpuVendorNVIDIA, gpuVendorSysfs}
+	if !reflect.DeepEqual(activeGPUVendors, want) {
+		t.Fatalf("probeGPUVendors() = %v; expected %v", activeGPUVendors, want)
+	}
+
+	if usage := getGPUUsage(); usage != 55 {
+		t.Errorf("getGPUUsage() = %.2f; expected 55", usage)
+	}
+	// 6144 of 24564 MiB on the NVIDIA GPU plus 4 and 8 GiB of 16 GiB on
+	// the amdgpu cards, all counted in bytes
+	wantMemory := float64(6144<<20+4<<30+8<<30) / float64(24564<<20+2*17163091968) * 100
+	if memory := getGPUMemory(); math.Abs(memory-wantMemory) > 0.01 {
+		t.Errorf("getGPUMemory() = %.2f; expected %.2f", memory, wantMemory)
+	}
+
+	// With rocm-smi also answering, the amdgpu cards belong to the AMD
+	// backend and sysfs only keeps the i915 card
+	if gpus := sysfsGPUsFor(sysfsRoot, []gpuVendor{gpuVendorAMD}); len(gpus) != 1 || gpus[0].Driver != "i915" {
+		t.Errorf("sysfsGPUsFor(amd active) = %+v; expected only the i915 card", gpus)
+	}
+}
+
+func TestRefreshGPUBackendsReprobes(t *testing.T) {
+	origRunner, origRoot := runner, sysfsRoot
+	origSelection, origVendors, origProbe := gpuSelection, activeGPUVendors, lastGPUProbe
+	defer func() {
+		runner, sysfsRoot = origRunner, origRoot
+		gpuSelection, activeGPUVendors, lastGPUProbe = origSelection, origVendors, origProbe
+	}()
+
+	sysfsRoot = t.TempDir()
+	runner = fixtureRunner{dir: filepath.Join("testdata", "gpu", "nvidia-no-driver")}
+	configureGPUs(gpuMode{auto: true})
+	if vendors := gpuVendors(); len(vendors) != 0 {
+		t.Fatalf("expected no backends before the driver loads, got %v", vendors)
+	}
+
+	// The driver comes up, but nothing changes until the re-probe interval passes
+	runner = fixtureRunner{dir: filepath.Join("testdata", "gpu", "nvidia-535-single")}
+	refreshGPUBackends(lastGPUProbe.Add(gpuReprobeInterval / 2))
+	if vendors := gpuVendors(); len(vendors) != 0 {
+		t.Fatalf("re-probed before the interval elapsed: %v", vendors)
+	}
+
+	refreshGPUBackends(lastGPUProbe.Add(gpuReprobeInterval))
+	if vendors := gpuVendors(); !reflect.DeepEqual(vendors, []gpuVendor{gpuVendorNVIDIA}) {
+		t.Errorf("after re-probe got %v; expected [nvidia]", vendors)
+	}
+
+	// An explicit selection is never re-probed
+	configureGPUs(gpuMode{})
+	refreshGPUBackends(lastGPUProbe.Add(time.Hour))
+	if vendors := gpuVendors(); len(vendors) != 0 {
+		t.Errorf("--gpu none re-probed to %v", vendors)
+	}
+}
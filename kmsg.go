@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Kinds of kernel events, in increasing severity; the values mark the
+// events timeline
+const (
+	kernelEventThermal = iota + 1
+	kernelEventHung
+	kernelEventSegfault
+	kernelEventOOM
+)
+
+var kernelEventNames = map[int]string{
+	kernelEventThermal:  "thermal",
+	kernelEventHung:     "hung-task",
+	kernelEventSegfault: "segfault",
+	kernelEventOOM:      "oom-kill",
+}
+
+// Timeline markers by kind
+var kernelEventMarkers = map[int]string{
+	kernelEventThermal:  "T",
+	kernelEventHung:     "H",
+	kernelEventSegfault: "S",
+	kernelEventOOM:      "O",
+}
+
+// KernelEvent is a kernel log message worth surfacing
+type KernelEvent struct {
+	// Seq numbers events in the order they were read
+	Seq     uint64
+	Time    time.Time
+	Kind    int
+	PID     int32
+	Command string
+	Message string
+}
+
+var (
+	// "Out of memory: Killed process 1234 (postgres) total-vm:..." and the
+	// same from a memory cgroup
+	oomKillPattern = regexp.MustCompile(`(?:Out of memory|Memory cgroup out of memory): Killed process (\d+) \(([^)]*)\)`)
+	// "nginx[1234]: segfault at 0 ip ... error 4 in libc.so.6"
+	segfaultPattern = regexp.MustCompile(`^(\S+)\[(\d+)\]: segfault at`)
+	// "INFO: task jbd2/sda1-8:312 blocked for more than 120 seconds."
+	hungTaskPattern = regexp.MustCompile(`INFO: task (.+):(\d+) blocked for more than \d+ seconds`)
+	// "CPU3: Core temperature above threshold, cpu clock throttled" and
+	// "thermal thermal_zone0: critical temperature reached"
+	thermalPattern = regexp.MustCompile(`temperature above threshold|critical temperature reached`)
+
+	// /dev/kmsg records: "<prio>,<seq>,<usec since boot>,<flags>;<message>"
+	kmsgPattern = regexp.MustCompile(`^\d+,\d+,(\d+),[^;]*;(.*)$`)
+	// dmesg and kern.log lines carry "[12345.678901]" seconds since boot
+	dmesgPattern = regexp.MustCompile(`\[\s*(\d+\.\d+)\]\s?(.*)$`)
+)
+
+// classifyKernelMessage returns the kind of event a message reports, with
+// the process it names, or ok false for any other message
+func classifyKernelMessage(msg string) (kind int, pid int32, command string, ok bool) {
+	parsePID := func(s string) int32 {
+		n, _ := strconv.ParseInt(s, 10, 32)
+		return int32(n)
+	}
+	if m := oomKillPattern.FindStringSubmatch(msg); m != nil {
+		return kernelEventOOM, parsePID(m[1]), m[2], true
+	}
+	if m := segfaultPattern.FindStringSubmatch(msg); m != nil {
+		return kernelEventSegfault, parsePID(m[2]), m[1], true
+	}
+	if m := hungTaskPattern.FindStringSubmatch(msg); m != nil {
+		return kernelEventHung, parsePID(m[2]), m[1], true
+	}
+	if thermalPattern.MatchString(msg) {
+		return kernelEventThermal, 0, "", true
+	}
+	return 0, 0, "", false
+}
+
+// parseKernelLine splits a /dev/kmsg record or a dmesg or syslog line into
+// its time and message. Times since boot are placed after boot; lines
+// without one are stamped with now.
+func parseKernelLine(line string, boot, now time.Time) (time.Time, string) {
+	if m := kmsgPattern.FindStringSubmatch(line); m != nil {
+		usec, _ := strconv.ParseInt(m[1], 10, 64)
+		return boot.Add(time.Duration(usec) * time.Microsecond), m[2]
+	}
+	if m := dmesgPattern.FindStringSubmatch(line); m != nil {
+		seconds, _ := strconv.ParseFloat(m[1], 64)
+		return boot.Add(time.Duration(seconds * float64(time.Second))), m[2]
+	}
+	return now, line
+}
+
+// bootTime derives the boot time from <root>/uptime
+func bootTime(root string, now time.Time) time.Time {
+	fields := strings.Fields(readSysfsString(filepath.Join(root, "uptime")))
+	if len(fields) == 0 {
+		return now
+	}
+	uptime, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return now
+	}
+	return now.Add(-time.Duration(uptime * float64(time.Second)))
+}
+
+// Events kept for the events view
+const maxKernelEvents = 500
+
+// oomBannerWindow is how long an OOM kill stays in the alert banner
+const oomBannerWindow = 5 * time.Minute
+
+// KernelLogSnapshot is what the kernel log reader has found so far
+type KernelLogSnapshot struct {
+	Path   string
+	Err    error
+	Events []KernelEvent
+	// Since is when sysmon started reading; earlier events are from the
+	// log's backlog
+	Since time.Time
+}
+
+// kernelLogReader tails the kernel log in the background
+type kernelLogReader struct {
+	mu     sync.Mutex
+	path   string
+	err    error
+	events []KernelEvent
+	seq    uint64
+	since  time.Time
+}
+
+var kernelLog = &kernelLogReader{}
+
+// start tails path until it fails; an empty path disables the reader.
+// /dev/kmsg is replayed from the oldest record the kernel still holds, but
+// a regular file such as kern.log is only followed from its current end:
+// its earlier lines span past boots, whose offsets since boot can't be
+// placed in time.
+func (k *kernelLogReader) start(path string) {
+	if path == "" {
+		return
+	}
+	k.mu.Lock()
+	k.path = path
+	k.since = time.Now()
+	k.mu.Unlock()
+
+	f, err := os.Open(path)
+	if err == nil {
+		var info os.FileInfo
+		if info, err = f.Stat(); err == nil && info.Mode().IsRegular() {
+			_, err = f.Seek(0, io.SeekEnd)
+		}
+		if err != nil {
+			f.Close()
+		}
+	}
+	if err != nil {
+		k.fail(err)
+		return
+	}
+	go k.tail(f)
+}
+
+// tail reads kernel log lines as they appear. /dev/kmsg blocks for new
+// records; a regular file is polled once a second at its end, like tail -f.
+func (k *kernelLogReader) tail(f *os.File) {
+	defer f.Close()
+
+	boot := bootTime(procfsRoot, time.Now())
+	reader := bufio.NewReaderSize(f, 8192)
+	var pending string
+	for {
+		chunk, err := reader.ReadString('\n')
+		pending += chunk
+		if strings.HasSuffix(pending, "\n") {
+			k.consume(strings.TrimSuffix(pending, "\n"), boot, time.Now())
+			pending = ""
+		}
+		switch {
+		case err == nil:
+		case errors.Is(err, io.EOF):
+			time.Sleep(time.Second)
+		case errors.Is(err, syscall.EPIPE):
+			// /dev/kmsg overwrote records before we read them; the next
+			// read continues with the oldest one left
+		default:
+			k.fail(err)
+			return
+		}
+	}
+}
+
+// consume records line if it reports an event
+func (k *kernelLogReader) consume(line string, boot, now time.Time) {
+	at, msg := parseKernelLine(line, boot, now)
+	kind, pid, command, ok := classifyKernelMessage(msg)
+	if !ok {
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.seq++
+	k.events = append(k.events, KernelEvent{Seq: k.seq, Time: at, Kind: kind, PID: pid, Command: command, Message: msg})
+	if len(k.events) > maxKernelEvents {
+		k.events = k.events[len(k.events)-maxKernelEvents:]
+	}
+}
+
+func (k *kernelLogReader) fail(err error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.err = err
+}
+
+// snapshot copies the events read so far
+func (k *kernelLogReader) snapshot() KernelLogSnapshot {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return KernelLogSnapshot{
+		Path:   k.path,
+		Err:    k.err,
+		Events: append([]KernelEvent(nil), k.events...),
+		Since:  k.since,
+	}
+}
+
+// recordKernelEvents marks the most severe event read since the previous
+// sample on the events timeline, returning the last sequence number seen.
+// Backlog from before sysmon started and events dated after now, which
+// can't be placed on it, aren't marked.
+func recordKernelEvents(h history, snap KernelLogSnapshot, lastSeq uint64, now time.Time) uint64 {
+	marker := 0
+	for _, e := range snap.Events {
+		if e.Seq <= lastSeq {
+			continue
+		}
+		lastSeq = e.Seq
+		if !e.Time.Before(snap.Since) && !e.Time.After(now) && e.Kind > marker {
+			marker = e.Kind
+		}
+	}
+	h.record("kernel:events", float64(marker))
+	return lastSeq
+}
+
+// recentOOMKills returns the OOM kills within window before now, newest
+// first
+func recentOOMKills(snap KernelLogSnapshot, now time.Time, window time.Duration) []KernelEvent {
+	var kills []KernelEvent
+	for i := len(snap.Events) - 1; i >= 0; i-- {
+		e := snap.Events[i]
+		if e.Kind == kernelEventOOM && now.Sub(e.Time) <= window && !e.Time.After(now) && !e.Time.Before(snap.Since) {
+			kills = append(kills, e)
+		}
+	}
+	return kills
+}
+
+// renderEventsView shows a timeline of CPU and memory usage with the kernel
+// events marked below it, then every event newest first
+func renderEventsView(m model) []string {
+	snap := m.stats.KernelLog
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	boldStyle := lipgloss.NewStyle().Bold(true)
+
+	if snap.Path == "" {
+		return []string{"(kernel log reading is disabled; set --kernel-log or \"kernel_log\")"}
+	}
+	lines := []string{boldStyle.Render("Kernel events from " + snap.Path)}
+	if snap.Err != nil {
+		lines = append(lines, fmt.Sprintf("(cannot read the kernel log: %v; run as root or point --kernel-log at a readable file)", snap.Err))
+	}
+
+	// Timeline: one column per sample, newest on the right
+	width := m.width - 8
+	if width > historyLength {
+		width = historyLength
+	}
+	if width > 0 {
+		var markers strings.Builder
+		kinds := m.history["kernel:events"].last(width)
+		markers.WriteString(strings.Repeat(" ", width-len(kinds)))
+		for _, kind := range kinds {
+			if marker, ok := kernelEventMarkers[int(kind)]; ok {
+				markers.WriteString(redStyle.Render(marker))
+			} else {
+				markers.WriteString(" ")
+			}
+		}
+		span := tickInterval * time.Duration(len(kinds))
+		lines = append(lines,
+			"CPU     "+sparkline(m.history["cpu"].last(width), width, 100),
+			"MEM     "+sparkline(m.history["mem"].last(width), width, 100),
+			"events  "+markers.String(),
+			fmt.Sprintf("        %-*s%s", width-3, "-"+formatDuration(span), "now"),
+			"        O oom-kill  S segfault  H hung task  T thermal",
+			"")
+	}
+
+	lines = append(lines, headerStyle.Render(fmt.Sprintf("%-19s %-9s %-8s %-16s %s", "TIME", "KIND", "PID", "COMMAND", "MESSAGE")))
+	if len(snap.Events) == 0 {
+		return append(lines, "(no OOM kills, segfaults, hung tasks or thermal events)")
+	}
+	for i := len(snap.Events) - 1; i >= 0; i-- {
+		e := snap.Events[i]
+		style := lipgloss.NewStyle()
+		if e.Kind == kernelEventOOM || e.Kind == kernelEventSegfault {
+			style = redStyle
+		}
+		lines = append(lines, fmt.Sprintf("%-19s %s %-8s %-16s %s",
+			e.Time.Format("2006-01-02 15:04:05"), style.Render(fmt.Sprintf("%-9s", kernelEventNames[e.Kind])),
+			formatPID(e.PID), truncateLeft(e.Command, 16), e.Message))
+	}
+	return lines
+}
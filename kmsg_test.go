@@ -0,0 +1,217 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyKernelMessage(t *testing.T) {
+	tests := []struct {
+		msg     string
+		kind    int
+		pid     int32
+		command string
+	}{
+		{"Out of memory: Killed process 4242 (postgres) total-vm:8388608kB", kernelEventOOM, 4242, "postgres"},
+		{"Memory cgroup out of memory: Killed process 5150 (java) total-vm:4194304kB", kernelEventOOM, 5150, "java"},
+		{"nginx[100]: segfault at 0 ip 00007f2a1c2b3c4d sp 00007ffd5e6f7a80 error 4", kernelEventSegfault, 100, "nginx"},
+		{"INFO: task jbd2/sda1-8:312 blocked for more than 120 seconds.", kernelEventHung, 312, "jbd2/sda1-8"},
+		{"CPU3: Package temperature above threshold, cpu clock throttled", kernelEventThermal, 0, ""},
+		{"thermal thermal_zone0: critical temperature reached, shutting down", kernelEventThermal, 0, ""},
+		{"usb 1-2: new high-speed USB device number 4 using xhci_hcd", 0, 0, ""},
+	}
+	for _, tt := range tests {
+		kind, pid, command, ok := classifyKernelMessage(tt.msg)
+		if ok != (tt.kind != 0) || kind != tt.kind || pid != tt.pid || command != tt.command {
+			t.Errorf("classifyKernelMessage(%q) = %d, %d, %q, %v; expected %d, %d, %q",
+				tt.msg, kind, pid, command, ok, tt.kind, tt.pid, tt.command)
+		}
+	}
+}
+
+func TestParseKernelLine(t *testing.T) {
+	boot := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	now := boot.Add(time.Hour)
+	tests := []struct {
+		line string
+		at   time.Time
+		msg  string
+	}{
+		{"3,1206,60500000,-;INFO: task kworker:312 blocked", boot.Add(60500 * time.Millisecond), "INFO: task kworker:312 blocked"},
+		{"Oct 18 09:12:01 host kernel: [   95.250000] eth0: link up", boot.Add(95250 * time.Millisecond), "eth0: link up"},
+		{"plain message", now, "plain message"},
+	}
+	for _, tt := range tests {
+		at, msg := parseKernelLine(tt.line, boot, now)
+		if !at.Equal(tt.at) || msg != tt.msg {
+			t.Errorf("parseKernelLine(%q) = %v, %q; expected %v, %q", tt.line, at, msg, tt.at, tt.msg)
+		}
+	}
+}
+
+// readKernelFixture feeds a fixture log through a reader that started
+// reading at since
+func readKernelFixture(t *testing.T, name string, boot, since time.Time) *kernelLogReader {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "kmsg", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := &kernelLogReader{path: "/dev/kmsg", since: since}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		k.consume(line, boot, since)
+	}
+	return k
+}
+
+func TestKernelLogReader(t *testing.T) {
+	boot := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	k := readKernelFixture(t, "kmsg", boot, boot.Add(20*time.Second))
+	snap := k.snapshot()
+
+	var kinds []string
+	for _, e := range snap.Events {
+		kinds = append(kinds, kernelEventNames[e.Kind])
+	}
+	if got := strings.Join(kinds, ","); got != "thermal,segfault,hung-task,oom-kill" {
+		t.Fatalf("events = %s; expected thermal,segfault,hung-task,oom-kill", got)
+	}
+	oom := snap.Events[3]
+	if oom.Seq != 4 || oom.PID != 4242 || oom.Command != "postgres" || !oom.Time.Equal(boot.Add(90250*time.Millisecond)) {
+		t.Errorf("OOM event = %+v", oom)
+	}
+
+	// The thermal event predates the reader, so only later ones are marked
+	h := make(history)
+	last := recordKernelEvents(h, snap, 0, boot.Add(2*time.Minute))
+	if last != 4 {
+		t.Errorf("last sequence = %d; expected 4", last)
+	}
+	if got := h["kernel:events"].last(1); got[0] != kernelEventOOM {
+		t.Errorf("timeline marker = %v; expected the OOM kill", got[0])
+	}
+	recordKernelEvents(h, snap, last, boot.Add(2*time.Minute))
+	if got := h["kernel:events"].last(1); got[0] != 0 {
+		t.Errorf("timeline marker without new events = %v; expected 0", got[0])
+	}
+
+	kills := recentOOMKills(snap, boot.Add(2*time.Minute), oomBannerWindow)
+	if len(kills) != 1 || kills[0].PID != 4242 {
+		t.Errorf("recentOOMKills = %+v; expected postgres", kills)
+	}
+	if kills := recentOOMKills(snap, boot.Add(time.Hour), oomBannerWindow); len(kills) != 0 {
+		t.Errorf("recentOOMKills an hour later = %+v; expected none", kills)
+	}
+}
+
+func TestKernelLogTail(t *testing.T) {
+	// The fixture's lines are from an earlier boot and must be skipped
+	backlog, err := os.ReadFile(filepath.Join("testdata", "kmsg", "kern.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "kern.log")
+	if err := os.WriteFile(path, backlog, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	k := &kernelLogReader{}
+	k.start(path)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteString("Oct 18 09:20:00 host kernel: [    1.000000] nginx[100]: segfault at 0 ip 00007f2a1c2b3c4d sp 00007ffd5e6f7a80 error 4\n")
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for len(k.snapshot().Events) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	snap := k.snapshot()
+	if len(snap.Events) != 1 || snap.Events[0].Command != "nginx" || snap.Events[0].PID != 100 {
+		t.Errorf("events from kern.log = %+v; expected only the appended segfault", snap.Events)
+	}
+
+	missing := &kernelLogReader{}
+	missing.start(filepath.Join("testdata", "kmsg", "missing"))
+	if missing.snapshot().Err == nil {
+		t.Error("reading a missing log reported no error")
+	}
+}
+
+func TestKernelEventsOutsideSession(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	snap := KernelLogSnapshot{
+		Path:  "/dev/kmsg",
+		Since: now.Add(-time.Hour),
+		Events: []KernelEvent{
+			// From a previous boot, dated before sysmon started
+			{Seq: 1, Time: now.Add(-48 * time.Hour), Kind: kernelEventOOM, PID: 5150, Command: "java"},
+			// Misdated into the future
+			{Seq: 2, Time: now.Add(10 * time.Minute), Kind: kernelEventOOM, PID: 4242, Command: "postgres"},
+		},
+	}
+	if kills := recentOOMKills(snap, now, oomBannerWindow); len(kills) != 0 {
+		t.Errorf("recentOOMKills = %+v; expected none", kills)
+	}
+	h := make(history)
+	if last := recordKernelEvents(h, snap, 0, now); last != 2 {
+		t.Errorf("last sequence = %d; expected 2", last)
+	}
+	if got := h["kernel:events"].last(1); got[0] != 0 {
+		t.Errorf("timeline marker = %v; expected none", got[0])
+	}
+}
+
+func TestRenderEventsView(t *testing.T) {
+	boot := time.Now().Add(-10 * time.Minute)
+	k := readKernelFixture(t, "kmsg", boot, boot)
+	m := model{width: 100, height: 30, history: make(history), view: viewEvents}
+	m.stats.KernelLog = k.snapshot()
+	for i := 0; i < 5; i++ {
+		m.history.record("cpu", 50)
+		m.history.record("mem", 30)
+	}
+	m.kernelSeq = recordKernelEvents(m.history, m.stats.KernelLog, 0, time.Now())
+
+	lines := renderEventsView(m)
+	view := stripAnsiCodes(strings.Join(lines, "\n"))
+	for _, want := range []string{"Kernel events from /dev/kmsg", "O", "oom-kill  4242     postgres", "segfault  100      nginx"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("events view is missing %q:\n%s", want, view)
+		}
+	}
+	// Newest first
+	if strings.Index(view, "oom-kill") > strings.Index(view, "thermal ") {
+		t.Errorf("events are not newest first:\n%s", view)
+	}
+
+	m.stats.KernelLog = KernelLogSnapshot{}
+	if lines := renderEventsView(m); !strings.Contains(lines[0], "disabled") {
+		t.Errorf("events view without a kernel log = %q", lines[0])
+	}
+}
+
+func TestViewOOMKillBanner(t *testing.T) {
+	now := time.Now()
+	m := model{width: 100, height: 12}
+	m.stats.CPUCores = []float64{10}
+	m.stats.KernelLog = KernelLogSnapshot{
+		Path:  "/dev/kmsg",
+		Since: now.Add(-time.Hour),
+		Events: []KernelEvent{
+			{Seq: 1, Time: now.Add(-2 * time.Minute), Kind: kernelEventOOM, PID: 4242, Command: "postgres"},
+		},
+	}
+	view := stripAnsiCodes(m.View())
+	if first := strings.SplitN(view, "\n", 2)[0]; !strings.Contains(first, "OOM killer: killed postgres (4242) 2m00s ago") {
+		t.Errorf("first line is not the OOM banner: %q", first)
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// leakConfig configures the leak detector
+type leakConfig struct {
+	// Window is how long a process's memory has to grow, as a Go duration
+	Window string `json:"window"`
+	// MinGrowthMiBPerHour is the slowest growth reported as a leak
+	MinGrowthMiBPerHour float64 `json:"min_growth_mib_per_hour"`
+}
+
+func defaultLeakConfig() leakConfig {
+	return leakConfig{Window: "30m", MinGrowthMiBPerHour: 10}
+}
+
+// validate checks the window is a positive duration and the growth positive
+func (c leakConfig) validate() error {
+	window, err := time.ParseDuration(c.Window)
+	if err != nil {
+		return fmt.Errorf("invalid leak detector window %q: %w", c.Window, err)
+	}
+	if window < time.Minute {
+		return fmt.Errorf("leak detector window %q is shorter than a minute", c.Window)
+	}
+	if c.MinGrowthMiBPerHour <= 0 {
+		return fmt.Errorf("leak detector min_growth_mib_per_hour must be positive, got %v", c.MinGrowthMiBPerHour)
+	}
+	return nil
+}
+
+// Samples kept per window and process; samples closer together than
+// window/leakSamplesPerWindow are skipped
+const leakSamplesPerWindow = 60
+
+// leakTolerance is how far, as a fraction, RSS may drop between samples and
+// still count as growing, since reclaim trims a few pages now and then
+const leakTolerance = 0.01
+
+// LeakSuspect is a process whose memory grew steadily across the window
+type LeakSuspect struct {
+	PID     int32
+	Command string
+	RSS     uint64
+	// BytesPerHour is the least-squares growth rate over the window
+	BytesPerHour float64
+	// Exhausts is when the growth would use up available memory, 0 when
+	// there's none left or it's further out than maxForecast
+	Exhausts time.Duration
+}
+
+// LeakReport is the leak detector's result for one sample
+type LeakReport struct {
+	Suspects  []LeakSuspect
+	Window    time.Duration
+	MinGrowth float64
+	// Since is when tracking started; nothing is reported for a window
+	Since time.Time
+}
+
+type rssPoint struct {
+	at  time.Time
+	rss uint64
+}
+
+// rssSeries is the RSS history of one process
+type rssSeries struct {
+	created int64
+	points  []rssPoint
+}
+
+// leakTracker records the RSS of every process over the window
+type leakTracker struct {
+	mu sync.Mutex
+	// window and minGrowth (bytes per hour) are set by configure
+	window    time.Duration
+	minGrowth float64
+	start     time.Time
+	series    map[int32]*rssSeries
+}
+
+var leakStats = &leakTracker{window: 30 * time.Minute, minGrowth: 10 << 20}
+
+// configure applies a validated leakConfig
+func (t *leakTracker) configure(c leakConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.window, _ = time.ParseDuration(c.Window)
+	t.minGrowth = c.MinGrowthMiBPerHour * (1 << 20)
+}
+
+// update records the RSS of procs and returns the processes that grew
+// steadily by at least the minimum rate over the whole window, fastest
+// first. available is the memory left to grow into.
+func (t *leakTracker) update(procs []ProcessInfo, available uint64, now time.Time) LeakReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.series == nil {
+		t.series = make(map[int32]*rssSeries)
+		t.start = now
+	}
+	report := LeakReport{Window: t.window, MinGrowth: t.minGrowth, Since: t.start}
+	spacing := t.window / leakSamplesPerWindow
+	cutoff := now.Add(-t.window)
+
+	seen := make(map[int32]bool, len(procs))
+	for _, p := range procs {
+		seen[p.PID] = true
+		s := t.series[p.PID]
+		if s == nil || s.created != p.Created {
+			s = &rssSeries{created: p.Created}
+			t.series[p.PID] = s
+		}
+		if n := len(s.points); n == 0 || now.Sub(s.points[n-1].at) >= spacing {
+			s.points = append(s.points, rssPoint{at: now, rss: p.RSS})
+		}
+		// Keep one sample from before the window so it is covered fully
+		for len(s.points) > 1 && !s.points[1].at.After(cutoff) {
+			s.points = s.points[1:]
+		}
+
+		if now.Sub(s.points[0].at) < t.window {
+			continue
+		}
+		slope, steady := rssGrowth(s.points)
+		perHour := slope * 3600
+		if !steady || perHour < t.minGrowth {
+			continue
+		}
+		suspect := LeakSuspect{PID: p.PID, Command: p.Command, RSS: p.RSS, BytesPerHour: perHour}
+		if available > 0 {
+			suspect.Exhausts = timeToZero(float64(available), -slope)
+		}
+		report.Suspects = append(report.Suspects, suspect)
+	}
+
+	for pid := range t.series {
+		if !seen[pid] {
+			delete(t.series, pid)
+		}
+	}
+
+	sort.Slice(report.Suspects, func(i, j int) bool {
+		return report.Suspects[i].BytesPerHour > report.Suspects[j].BytesPerHour
+	})
+	return report
+}
+
+// rssGrowth fits a line to the samples, returning its slope in bytes per
+// second and whether RSS grew without dropping by more than leakTolerance
+// between samples
+func rssGrowth(points []rssPoint) (float64, bool) {
+	if len(points) < 3 {
+		return 0, false
+	}
+	steady := points[len(points)-1].rss > points[0].rss
+	for i := 1; i < len(points); i++ {
+		if float64(points[i].rss) < float64(points[i-1].rss)*(1-leakTolerance) {
+			steady = false
+		}
+	}
+
+	times := make([]time.Time, len(points))
+	values := make([]float64, len(points))
+	for i, p := range points {
+		times[i] = p.at
+		values[i] = float64(p.rss)
+	}
+	slope, ok := fitSlope(times, values)
+	return slope, steady && ok
+}
+
+// renderLeaksPanel lists the processes suspected of leaking memory
+func renderLeaksPanel(m model) []string {
+	r := m.stats.Leaks
+	// Fixed columns: PID (10) + RSS (9) + GROWTH (11) + EXHAUSTS IN (12) + spacing (4) = 46
+	commandWidth := m.width - 46
+	if commandWidth < minCommandWidth {
+		commandWidth = minCommandWidth
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	lines := []string{headerStyle.Render(fmt.Sprintf("%-10s %-*s %9s %11s %12s",
+		"LEAKS PID", commandWidth, "COMMAND", "RSS", "GROWTH", "EXHAUSTS IN"))}
+
+	if len(r.Suspects) == 0 {
+		if elapsed := time.Since(r.Since); r.Since.IsZero() || elapsed < r.Window {
+			return append(lines, fmt.Sprintf("(watching process memory; leaks are reported after %s of samples)", formatDuration(r.Window)))
+		}
+		return append(lines, fmt.Sprintf("(no process grew steadily by more than %s/h over %s)",
+			formatBytes(r.MinGrowth), formatDuration(r.Window)))
+	}
+
+	for _, s := range r.Suspects {
+		exhausts := "-"
+		style := lipgloss.NewStyle()
+		if s.Exhausts > 0 {
+			exhausts = formatDuration(s.Exhausts)
+			switch {
+			case s.Exhausts < time.Hour:
+				style = redStyle
+			case s.Exhausts < 24*time.Hour:
+				style = yellowStyle
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%-10d %-*s %9s %11s %s",
+			s.PID, commandWidth, truncateLeft(s.Command, commandWidth), formatBytes(float64(s.RSS)),
+			formatBytes(s.BytesPerHour)+"/h", style.Render(fmt.Sprintf("%12s", exhausts))))
+	}
+	return lines
+}
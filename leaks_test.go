@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLeakTrackerUpdate(t *testing.T) {
+	tracker := &leakTracker{window: 10 * time.Minute, minGrowth: 10 << 20}
+	start := time.Unix(100000, 0)
+
+	// Samples every 30s for 12 minutes: a steady 60M/h leak, a process that
+	// grows as fast but frees memory along the way, and a slow grower
+	var report LeakReport
+	for i := 0; i <= 24; i++ {
+		sawtooth := uint64(100<<20) + uint64(i)*(512<<10)
+		if i%4 == 3 {
+			sawtooth -= 4 << 20
+		}
+		procs := []ProcessInfo{
+			{PID: 1, Created: 1, Command: "leaky", RSS: uint64(200<<20) + uint64(i)*(512<<10)},
+			{PID: 2, Created: 1, Command: "cache", RSS: sawtooth},
+			{PID: 3, Created: 1, Command: "slow", RSS: uint64(50<<20) + uint64(i)*(16<<10)},
+		}
+		report = tracker.update(procs, 3<<30, start.Add(time.Duration(i)*30*time.Second))
+		if i < 20 && len(report.Suspects) > 0 {
+			t.Fatalf("sample %d reported %+v before a full window", i, report.Suspects)
+		}
+	}
+
+	if len(report.Suspects) != 1 || report.Suspects[0].PID != 1 {
+		t.Fatalf("suspects = %+v; expected only the steady grower", report.Suspects)
+	}
+	s := report.Suspects[0]
+	if s.BytesPerHour < 59<<20 || s.BytesPerHour > 61<<20 {
+		t.Errorf("growth = %s/h; expected 60M/h", formatBytes(s.BytesPerHour))
+	}
+	// 3G available at 60M/h is 51.2 hours
+	if s.Exhausts < 51*time.Hour || s.Exhausts > 52*time.Hour {
+		t.Errorf("exhausts in %v; expected about 51h", s.Exhausts)
+	}
+
+	// The PID is reused by a new process, whose history starts over
+	report = tracker.update([]ProcessInfo{{PID: 1, Created: 2, RSS: 900 << 20}}, 3<<30, start.Add(13*time.Minute))
+	if len(report.Suspects) != 0 || len(tracker.series) != 1 || len(tracker.series[1].points) != 1 {
+		t.Errorf("a reused PID kept its history: %+v", tracker.series[1])
+	}
+}
+
+func TestLeakTrackerSlowGrowth(t *testing.T) {
+	tracker := &leakTracker{window: 10 * time.Minute}
+	start := time.Unix(100000, 0)
+
+	// A byte every 30s against a petabyte available would overflow a Duration
+	var report LeakReport
+	for i := 0; i <= 24; i++ {
+		procs := []ProcessInfo{{PID: 1, Created: 1, Command: "slow", RSS: uint64(50<<20) + uint64(i)}}
+		report = tracker.update(procs, 1<<50, start.Add(time.Duration(i)*30*time.Second))
+	}
+	if len(report.Suspects) != 1 {
+		t.Fatalf("suspects = %+v; expected the slow grower", report.Suspects)
+	}
+	if s := report.Suspects[0]; s.Exhausts != 0 {
+		t.Errorf("exhausts in %v; expected 0 beyond the forecast limit", s.Exhausts)
+	}
+}
+
+func TestLeakConfigValidate(t *testing.T) {
+	if err := defaultLeakConfig().validate(); err != nil {
+		t.Errorf("default leak config is invalid: %v", err)
+	}
+	for _, c := range []leakConfig{
+		{Window: "soon", MinGrowthMiBPerHour: 1},
+		{Window: "10s", MinGrowthMiBPerHour: 1},
+		{Window: "1h", MinGrowthMiBPerHour: 0},
+	} {
+		if err := c.validate(); err == nil {
+			t.Errorf("%+v passed validation", c)
+		}
+	}
+}
+
+func TestRenderLeaksPanel(t *testing.T) {
+	m := model{width: 100, stats: SystemStats{Leaks: LeakReport{
+		Window: 30 * time.Minute, MinGrowth: 10 << 20, Since: time.Now(),
+	}}}
+	if got := stripAnsiCodes(strings.Join(renderLeaksPanel(m), "\n")); !strings.Contains(got, "after 30m00s of samples") {
+		t.Errorf("panel during the first window:\n%s", got)
+	}
+
+	m.stats.Leaks.Since = time.Now().Add(-time.Hour)
+	if got := stripAnsiCodes(strings.Join(renderLeaksPanel(m), "\n")); !strings.Contains(got, "more than 10.0M/h over 30m00s") {
+		t.Errorf("panel without suspects:\n%s", got)
+	}
+
+	m.stats.Leaks.Suspects = []LeakSuspect{{PID: 4242, Command: "/usr/bin/worker", RSS: 3 << 30, BytesPerHour: 120 << 20, Exhausts: 40 * time.Minute}}
+	got := stripAnsiCodes(strings.Join(renderLeaksPanel(m), "\n"))
+	for _, want := range []string{"4242", "/usr/bin/worker", "3.0G", "120.0M/h", "40m00s"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("panel is missing %q:\n%s", want, got)
+		}
+	}
+}
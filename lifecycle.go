@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Process lifecycle events
+const (
+	processStarted = "start"
+	processExited  = "exit"
+)
+
+// Events kept for the process log
+const maxProcessEvents = 1000
+
+// ProcessEvent is a process seen starting or found gone
+type ProcessEvent struct {
+	// Time is when a process started, or the sample that found it gone
+	Time    time.Time
+	Kind    string
+	PID     int32
+	Command string
+	// Lifetime is from the start to the last sample the process was seen
+	// in, so a process that exited shortly after a sample ran up to one
+	// tick longer; 0 for starts
+	Lifetime time.Duration
+	// PeakCPU and PeakRSS are the highest seen across the samples; the CPU
+	// is averaged over the process's lifetime up to each sample
+	PeakCPU float64
+	PeakRSS uint64
+}
+
+// ProcessLog is the process lifecycle tracker's state for one sample
+type ProcessLog struct {
+	Events []ProcessEvent
+	// ForksPerS is the rate of new tasks, processes and threads, from
+	// /proc/stat; a rate without starts in the log points at processes
+	// living shorter than a tick
+	ForksPerS float64
+	// Since is when tracking started; processes running then aren't
+	// logged as starts
+	Since time.Time
+}
+
+// trackedProcess is what the tracker remembers of a running process
+type trackedProcess struct {
+	created  int64
+	command  string
+	lastSeen time.Time
+	peakCPU  float64
+	peakRSS  uint64
+}
+
+// processLifecycleTracker diffs the process set between samples
+type processLifecycleTracker struct {
+	mu        sync.Mutex
+	running   map[int32]*trackedProcess
+	events    []ProcessEvent
+	since     time.Time
+	forks     uint64
+	forksTime time.Time
+}
+
+var processLifecycle = &processLifecycleTracker{}
+
+// update compares procs with the previous sample, logging the processes
+// that started and exited since, in time order: starts are dated by the
+// process's start time and exits by the sample that found them gone. forks
+// is the /proc/stat fork counter. The first call only records the running
+// processes.
+func (t *processLifecycleTracker) update(procs []ProcessInfo, forks uint64, now time.Time) ProcessLog {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	first := t.running == nil
+	if first {
+		t.running = make(map[int32]*trackedProcess)
+		t.since = now
+	}
+
+	log := ProcessLog{Since: t.since}
+	if elapsed := now.Sub(t.forksTime).Seconds(); !t.forksTime.IsZero() && elapsed > 0 {
+		log.ForksPerS = counterDelta(forks, t.forks) / elapsed
+	}
+	t.forks, t.forksTime = forks, now
+
+	var started []ProcessEvent
+	seen := make(map[int32]bool, len(procs))
+	for _, p := range procs {
+		seen[p.PID] = true
+		tp := t.running[p.PID]
+		if tp != nil && tp.created != p.Created {
+			// The PID was reused between samples
+			t.exited(p.PID, tp, now)
+			tp = nil
+		}
+		if tp == nil {
+			tp = &trackedProcess{created: p.Created, command: p.Command}
+			t.running[p.PID] = tp
+			if !first {
+				started = append(started, ProcessEvent{Time: time.UnixMilli(p.Created), Kind: processStarted, PID: p.PID, Command: p.Command, PeakCPU: p.CPU, PeakRSS: p.RSS})
+			}
+		}
+		tp.lastSeen = now
+		if p.CPU > tp.peakCPU {
+			tp.peakCPU = p.CPU
+		}
+		if p.RSS > tp.peakRSS {
+			tp.peakRSS = p.RSS
+		}
+	}
+	for pid, tp := range t.running {
+		if !seen[pid] {
+			t.exited(pid, tp, now)
+			delete(t.running, pid)
+		}
+	}
+	t.events = append(t.events, started...)
+	sort.SliceStable(t.events, func(i, j int) bool {
+		return t.events[i].Time.Before(t.events[j].Time)
+	})
+	if len(t.events) > maxProcessEvents {
+		t.events = t.events[len(t.events)-maxProcessEvents:]
+	}
+
+	log.Events = append([]ProcessEvent(nil), t.events...)
+	return log
+}
+
+// exited logs the exit of a tracked process
+func (t *processLifecycleTracker) exited(pid int32, tp *trackedProcess, now time.Time) {
+	t.events = append(t.events, ProcessEvent{
+		Time:     now,
+		Kind:     processExited,
+		PID:      pid,
+		Command:  tp.command,
+		Lifetime: tp.lastSeen.Sub(time.UnixMilli(tp.created)),
+		PeakCPU:  tp.peakCPU,
+		PeakRSS:  tp.peakRSS,
+	})
+}
+
+// readForks reads the number of tasks forked since boot from <root>/stat
+func readForks(root string) uint64 {
+	f, err := os.Open(filepath.Join(root, "stat"))
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "processes" {
+			n, _ := strconv.ParseUint(fields[1], 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+// recordProcessLogHistory records the fork rate for the process log sparkline
+func recordProcessLogHistory(h history, log ProcessLog) {
+	h.record("procs:forks", log.ForksPerS)
+}
+
+// exportProcessLog writes the events to a CSV file in dir, oldest first,
+// returning its path
+func exportProcessLog(dir string, events []ProcessEvent, now time.Time) (string, error) {
+	path := filepath.Join(dir, "sysmon-processes-"+now.Format("20060102-150405")+".csv")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"time", "event", "pid", "command", "lifetime_seconds", "peak_cpu_percent", "peak_rss_bytes"})
+	for _, e := range events {
+		lifetime := ""
+		if e.Kind == processExited {
+			lifetime = strconv.FormatFloat(e.Lifetime.Seconds(), 'f', 0, 64)
+		}
+		w.Write([]string{
+			e.Time.Format(time.RFC3339),
+			e.Kind,
+			strconv.Itoa(int(e.PID)),
+			e.Command,
+			lifetime,
+			strconv.FormatFloat(e.PeakCPU, 'f', 1, 64),
+			strconv.FormatUint(e.PeakRSS, 10),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// handleProcessLogKey exports the process log to the current directory on
+// e, reporting the file's full path
+func handleProcessLogKey(m model, key string) (model, bool) {
+	if key != "e" {
+		return m, false
+	}
+	path, err := exportProcessLog(".", m.stats.ProcessLog.Events, time.Now())
+	if abs, absErr := filepath.Abs(path); err == nil && absErr == nil {
+		path = abs
+	}
+	if err != nil {
+		m.processLogStatus = fmt.Sprintf("export failed: %v", err)
+	} else {
+		m.processLogStatus = fmt.Sprintf("exported %d events to %s", len(m.stats.ProcessLog.Events), path)
+	}
+	return m, true
+}
+
+// renderProcessLogView lists process starts and exits, newest first, below
+// a sparkline of the fork rate
+func renderProcessLogView(m model) []string {
+	log := m.stats.ProcessLog
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	boldStyle := lipgloss.NewStyle().Bold(true)
+
+	starts, exits := 0, 0
+	for _, e := range log.Events {
+		if e.Kind == processStarted {
+			starts++
+		} else {
+			exits++
+		}
+	}
+	lines := []string{boldStyle.Render(fmt.Sprintf("Process starts and exits: %d started, %d exited", starts, exits))}
+
+	// Label (10) + rates (24)
+	width := m.width - 34
+	if width > historyLength {
+		width = historyLength
+	}
+	if width > 0 {
+		forks := m.history["procs:forks"].last(width)
+		var peak float64
+		for _, v := range forks {
+			if v > peak {
+				peak = v
+			}
+		}
+		lines = append(lines, fmt.Sprintf("forks/s   %s %.1f now, %.1f peak", sparkline(forks, width, peak), log.ForksPerS, peak))
+	}
+	lines = append(lines, "Processes living shorter than a tick only show in the fork rate, which also counts threads")
+	if m.processLogStatus != "" {
+		lines = append(lines, m.processLogStatus)
+	}
+	lines = append(lines, "")
+
+	// Fixed columns: TIME (8) + EVENT (5) + PID (8) + LIFETIME (8) + PEAK CPU% (9) + PEAK RSS (8) + spacing (6) = 52
+	commandWidth := m.width - 52
+	if commandWidth < minCommandWidth {
+		commandWidth = minCommandWidth
+	}
+	lines = append(lines, headerStyle.Render(fmt.Sprintf("%-8s %-5s %-8s %8s %9s %8s %-*s",
+		"TIME", "EVENT", "PID", "LIFETIME", "PEAK CPU%", "PEAK RSS", commandWidth, "COMMAND")))
+	if len(log.Events) == 0 {
+		if log.Since.IsZero() {
+			return append(lines, "(waiting for the first sample)")
+		}
+		return append(lines, fmt.Sprintf("(no process started or exited since %s)", log.Since.Format("15:04:05")))
+	}
+	for i := len(log.Events) - 1; i >= 0; i-- {
+		e := log.Events[i]
+		lifetime := "-"
+		event := greenStyle.Render(fmt.Sprintf("%-5s", e.Kind))
+		if e.Kind == processExited {
+			lifetime = formatDuration(e.Lifetime)
+			event = yellowStyle.Render(fmt.Sprintf("%-5s", e.Kind))
+		}
+		lines = append(lines, fmt.Sprintf("%-8s %s %-8d %8s %s %8s %s",
+			e.Time.Format("15:04:05"), event, e.PID, lifetime,
+			getColorStyle(e.PeakCPU).Render(fmt.Sprintf("%9.1f", e.PeakCPU)),
+			formatBytes(float64(e.PeakRSS)), truncateLeft(e.Command, commandWidth)))
+	}
+	return lines
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessLifecycleTrackerUpdate(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tracker := &processLifecycleTracker{}
+
+	initial := []ProcessInfo{
+		{PID: 1, Command: "/sbin/init", Created: start.Add(-time.Hour).UnixMilli(), CPU: 0.1, RSS: 8 << 20},
+		{PID: 100, Command: "/usr/bin/make", Created: start.Add(-time.Minute).UnixMilli(), CPU: 5, RSS: 20 << 20},
+	}
+	if log := tracker.update(initial, 1000, start); len(log.Events) != 0 || log.ForksPerS != 0 {
+		t.Fatalf("first update = %+v; expected a baseline without events", log)
+	}
+
+	// cc starts and make peaks
+	second := start.Add(3 * time.Second)
+	cc := ProcessInfo{PID: 200, Command: "/usr/bin/cc", Created: start.Add(time.Second).UnixMilli(), CPU: 95, RSS: 300 << 20}
+	log := tracker.update([]ProcessInfo{initial[0], {PID: 100, Command: "/usr/bin/make", Created: initial[1].Created, CPU: 40, RSS: 10 << 20}, cc}, 1030, second)
+	if len(log.Events) != 1 || log.Events[0].Kind != processStarted || log.Events[0].PID != 200 || !log.Events[0].Time.Equal(start.Add(time.Second)) {
+		t.Fatalf("events after cc started = %+v", log.Events)
+	}
+	if log.ForksPerS != 10 {
+		t.Errorf("ForksPerS = %v; expected 10", log.ForksPerS)
+	}
+
+	// make and cc exit, and cc's PID is reused
+	third := start.Add(6 * time.Second)
+	reused := ProcessInfo{PID: 200, Command: "/usr/bin/ld", Created: start.Add(5 * time.Second).UnixMilli(), CPU: 50, RSS: 50 << 20}
+	log = tracker.update([]ProcessInfo{initial[0], reused}, 1030, third)
+	byKey := make(map[string]ProcessEvent)
+	for _, e := range log.Events[1:] {
+		byKey[e.Kind+" "+e.Command] = e
+	}
+	if len(log.Events) != 4 {
+		t.Fatalf("events = %+v; expected cc's start, two exits and ld's start", log.Events)
+	}
+	// ld started before the sample that found make and cc gone
+	if e := log.Events[1]; e.Kind != processStarted || e.Command != "/usr/bin/ld" {
+		t.Errorf("events = %+v; expected them in time order", log.Events)
+	}
+	if e := byKey["exit /usr/bin/make"]; e.PID != 100 || e.Lifetime != time.Minute+3*time.Second || e.PeakCPU != 40 || e.PeakRSS != 20<<20 || !e.Time.Equal(third) {
+		t.Errorf("make exit = %+v", e)
+	}
+	if e := byKey["exit /usr/bin/cc"]; e.PID != 200 || e.Lifetime != 2*time.Second || e.PeakCPU != 95 {
+		t.Errorf("cc exit = %+v", e)
+	}
+	if e := byKey["start /usr/bin/ld"]; e.PID != 200 {
+		t.Errorf("ld start = %+v", e)
+	}
+}
+
+func TestReadForks(t *testing.T) {
+	if got := readForks(filepath.Join("testdata", "proc")); got != 86031 {
+		t.Errorf("readForks = %d; expected 86031", got)
+	}
+	if got := readForks(t.TempDir()); got != 0 {
+		t.Errorf("readForks without a stat file = %d; expected 0", got)
+	}
+}
+
+func TestExportProcessLog(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	events := []ProcessEvent{
+		{Time: at, Kind: processStarted, PID: 200, Command: "/usr/bin/cc", PeakCPU: 95, PeakRSS: 1024},
+		{Time: at.Add(3 * time.Second), Kind: processExited, PID: 200, Command: "/usr/bin/cc", Lifetime: 2 * time.Second, PeakCPU: 95.25, PeakRSS: 2048},
+	}
+	path, err := exportProcessLog(t.TempDir(), events, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "sysmon-processes-20240501-120000.csv" {
+		t.Errorf("export path = %s", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "2024-05-01T12:00:03Z,exit,200,/usr/bin/cc,2,95.2,2048"
+	if len(records) != 3 || strings.Join(records[2], ",") != want {
+		t.Errorf("exported records = %v; expected the last to be %s", records, want)
+	}
+}
+
+func TestHandleProcessLogKey(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	m, handled := handleProcessLogKey(model{}, "e")
+	if !handled || !strings.HasPrefix(m.processLogStatus, "exported 0 events to "+dir+string(filepath.Separator)) {
+		t.Errorf("status after export = %q; expected the full path under %s", m.processLogStatus, dir)
+	}
+}
+
+func TestRenderProcessLogView(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	m := model{width: 100, height: 20, history: make(history), view: viewProcessLog}
+	m.stats.ProcessLog = ProcessLog{
+		Since:     at,
+		ForksPerS: 12,
+		Events: []ProcessEvent{
+			{Time: at.Add(time.Second), Kind: processStarted, PID: 200, Command: "/usr/bin/cc", PeakCPU: 95, PeakRSS: 300 << 20},
+			{Time: at.Add(6 * time.Second), Kind: processExited, PID: 200, Command: "/usr/bin/cc", Lifetime: 2 * time.Second, PeakCPU: 95, PeakRSS: 300 << 20},
+		},
+	}
+	m.history.record("procs:forks", 12)
+
+	view := stripAnsiCodes(strings.Join(renderProcessLogView(m), "\n"))
+	for _, want := range []string{"1 started, 1 exited", "12.0 now", "12:00:06 exit  200            2s      95.0   300.0M /usr/bin/cc"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("process log view is missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Index(view, "12:00:06 exit") > strings.Index(view, "12:00:01 start") {
+		t.Errorf("events are not newest first:\n%s", view)
+	}
+
+	m.stats.ProcessLog = ProcessLog{}
+	if view := stripAnsiCodes(strings.Join(renderProcessLogView(m), "\n")); !strings.Contains(view, "waiting for the first sample") {
+		t.Errorf("empty process log view:\n%s", view)
+	}
+}
@@ -1,13 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
-	"os/exec"
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -22,8 +21,44 @@ type SystemStats struct {
 	GPUUsage    float64
 	MemoryUsage float64
 	GPUMemory   float64
+	GPUTemp     float64
 	CPUCores    []float64
+	// CPUTimes breaks each core's time down by state, parallel to CPUCores
+	CPUTimes []CPUTimes
+	// CPUTopology places each core on a socket, NUMA node and physical core,
+	// and CPUFreq has its clock, both parallel to CPUCores
+	CPUTopology []CPUTopology
+	CPUFreq     []CPUFreq
 	Processes   []ProcessInfo
+	Disks       []DiskStats
+	Filesystems []FilesystemStats
+	Network     []NetworkStats
+	// MemoryDetail is the breakdown behind MemoryUsage
+	MemoryDetail MemoryDetail
+	Pressure     []Pressure
+	Sensors      []Sensor
+	Power        PowerStats
+	// Container is set when CPUUsage and MemoryUsage are relative to
+	// sysmon's cgroup limits instead of the host
+	Container ContainerStats
+	// Workloads sums the usage of every process, not just the top ones, by
+	// container, pod or unit
+	Workloads []WorkloadUsage
+	// ProcessIOUnreadable counts processes whose I/O counters need root
+	ProcessIOUnreadable int
+	// Leaks lists processes whose memory has grown steadily
+	Leaks LeakReport
+	// MemoryForecast is the trend of available memory and swap
+	MemoryForecast MemoryForecast
+	// KernelLog holds the OOM kills, segfaults, hung tasks and thermal
+	// events read from the kernel log
+	KernelLog KernelLogSnapshot
+	// ProcessLog lists the processes that started and exited
+	ProcessLog ProcessLog
+	// GPUs has per-GPU stats, collected only when an alert rule uses them
+	GPUs []GPUDevice
+	// Alerts lists the firing alert rules
+	Alerts []Alert
 }
 
 type ProcessInfo struct {
@@ -31,75 +66,139 @@ type ProcessInfo struct {
 	CPU     float64
 	Memory  float32
 	Command string
+	// Workload is the container, pod or systemd unit the process runs in
+	Workload Workload
+	// ReadBytesPerS and WriteBytesPerS are storage I/O rates; HasIO is false
+	// when /proc/<pid>/io isn't readable
+	ReadBytesPerS  float64
+	WriteBytesPerS float64
+	HasIO          bool
+	// RSS, VMS and Shared are from /proc/<pid>/statm; PSS, USS and Swap
+	// from smaps_rollup when HasSmaps is set
+	RSS      uint64
+	VMS      uint64
+	Shared   uint64
+	PSS      uint64
+	USS      uint64
+	Swap     uint64
+	HasSmaps bool
+	// Created is the start time in milliseconds since the epoch, which
+	// tells a reused PID from the process before it
+	Created int64
+	// OOMScore is /proc/<pid>/oom_score, -1 when unreadable
+	OOMScore int
 }
 
 type model struct {
 	stats  SystemStats
 	width  int
 	height int
+	// panels records which optional dashboard panels are shown
+	panels map[string]bool
+	// history holds recent samples for sparklines
+	history history
+	// cpuDetailed draws per-core bars stacked by CPU state instead of a single busy bar
+	cpuDetailed bool
+	// coreLayout picks bars or heatmap for the core grid, coreGrouped splits it
+	// by NUMA node and coresCollapsed shows only per-group averages
+	coreLayout     coreLayout
+	coreGrouped    bool
+	coresCollapsed bool
+	// showVirtualIfaces includes loopback, veth and bridge interfaces in the network panel
+	showVirtualIfaces bool
+	// view is the screen currently shown and scroll its first visible line
+	view   viewMode
+	scroll int
+	// processRollup replaces the process list with per-container totals
+	processRollup bool
+	// processSort is the process list's sort column, largest first unless
+	// processReverse is set
+	processSort    processSort
+	processReverse bool
+	// processBytes shows process memory in bytes instead of percentages
+	processBytes bool
+	// connections backs the connections view
+	connections ConnectionsSnapshot
+	// cgroups backs the cgroup view, with the selected row and the cgroups
+	// expanded to show their children and processes
+	cgroups        CgroupTree
+	cgroupCursor   int
+	cgroupExpanded map[string]bool
+	// services backs the services view, sorted by servicesSort
+	services        ServicesSnapshot
+	servicesSort    serviceSort
+	servicesReverse bool
+	// kernelSeq is the last kernel event marked on the events timeline
+	kernelSeq uint64
+	// processLogStatus reports the last export of the process log
+	processLogStatus string
 }
 
 type tickMsg struct{}
 
+// tickInterval is how often stats are collected
+const tickInterval = 3 * time.Second
+
 // Constants for process list formatting
 const (
 	// Width of fixed columns in the process list based on "%-10d %s  %s  %s\n":
-	// PID (10) + space (1) + CPU% (6) + spaces (2) + MEM% (5) + spaces (2) = 26
+	// PID (10) + space (1) + CPU% (6) + spaces (2) + MEM% (5) + spaces (2) = 26,
+	// with MEM% as the memory column
 	fixedColumnsWidth = 26
+	// Width of the READ/s and WRITE/s columns: READ/s (8) + spaces (2) + WRITE/s (8) +
+	// spaces (2) = 20, left out on narrow terminals unless sorting by I/O
+	ioColumnsWidth = 20
+	// Width of the CONTAINER column, enough for "containerd:" and a 12 character ID,
+	// which is only shown when a listed process belongs to a workload
+	containerColumnWidth = 24
 	// Minimum width for the COMMAND column to show something useful
 	minCommandWidth = 10
 )
 
-// GPU vendor type
-type gpuVendor int
-
-const (
-	gpuVendorNone gpuVendor = iota
-	gpuVendorNVIDIA
-	gpuVendorAMD
-)
-
-// Cache for detected GPU vendor to avoid repeated command execution
-var detectedGPUVendor gpuVendor
-var gpuVendorOnce sync.Once
-
 func main() {
-	// Detect GPU vendor once at startup
-	detectGPUVendor()
-
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
+	cfg, err := parseFlags(os.Args[1:], os.Stderr)
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	// gopsutil reads HOST_PROC and HOST_SYS, so point it at the same roots
+	procfsRoot, sysfsRoot = cfg.ProcRoot, cfg.SysRoot
+	os.Setenv("HOST_PROC", procfsRoot)
+	os.Setenv("HOST_SYS", sysfsRoot)
+
+	// Select GPU backends, probing once at startup in auto mode
+	gpuMode, _ := parseGPUMode(cfg.GPU)
+	configureGPUs(gpuMode)
+	fsFilter = cfg.Filesystems
+	pressureCgroups = cfg.PressureCgroups
+	perspective = cfg.Perspective
+	readSmaps = cfg.ProcessSmaps
+	leakStats.configure(cfg.LeakDetector)
+	memoryForecast.configure(cfg.MemoryForecast)
+	kernelLog.start(cfg.KernelLog)
+	alertRules.configure(cfg.Alerts)
+
+	p := tea.NewProgram(initialModel(cfg), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running application: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-// detectGPUVendor detects which GPU vendor tools are available and caches the result
-func detectGPUVendor() {
-	gpuVendorOnce.Do(func() {
-		// Try NVIDIA first
-		cmd := exec.Command("nvidia-smi", "--query-gpu=utilization.gpu", "--format=csv,noheader,nounits")
-		if err := cmd.Run(); err == nil {
-			detectedGPUVendor = gpuVendorNVIDIA
-			return
-		}
-
-		// Try AMD
-		cmd = exec.Command("rocm-smi", "--showuse")
-		if err := cmd.Run(); err == nil {
-			detectedGPUVendor = gpuVendorAMD
-			return
-		}
-
-		// No GPU tools available
-		detectedGPUVendor = gpuVendorNone
-	})
-}
-
-func initialModel() model {
-	return model{
-		stats: collectStats(),
+func initialModel(cfg config) model {
+	m := model{
+		stats:   collectStats(),
+		panels:  make(map[string]bool),
+		history: make(history),
+	}
+	for _, name := range cfg.Panels {
+		m.panels[name] = true
 	}
+	return m
 }
 
 func (m model) Init() tea.Cmd {
@@ -118,13 +217,74 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "q", "Q", "ctrl+c", "esc":
 			return m, tea.Quit
 		}
+		if m, cmd, ok := m.handleViewKey(msg.String()); ok {
+			return m, cmd
+		}
+
+		// The remaining keys change the dashboard, so other views ignore them
+		if m.view != viewDashboard {
+			return m, nil
+		}
+		switch msg.String() {
+		case "v":
+			m.showVirtualIfaces = !m.showVirtualIfaces
+			return m, nil
+		case "c":
+			m.cpuDetailed = !m.cpuDetailed
+			return m, nil
+		case "h":
+			m.coreLayout = m.coreLayout.next()
+			return m, nil
+		case "s":
+			m.coreGrouped = !m.coreGrouped
+			return m, nil
+		case "x":
+			m.coresCollapsed = !m.coresCollapsed
+			return m, nil
+		case "o":
+			m.processRollup = !m.processRollup
+			return m, nil
+		}
+		if m, ok := m.handleProcessSortKey(msg.String()); ok {
+			return m, nil
+		}
+		if m.panels != nil {
+			m.togglePanel(msg.String())
+		}
 		return m, nil
 
 	case tickMsg:
+		if refresh := views[m.view].refresh; refresh != nil {
+			return m, tea.Batch(tick(), updateStats(), refresh())
+		}
 		return m, tea.Batch(tick(), updateStats())
 
+	case connectionsMsg:
+		m.connections = ConnectionsSnapshot(msg)
+		return m, nil
+
+	case cgroupTreeMsg:
+		m.cgroups = CgroupTree(msg)
+		return m, nil
+
+	case servicesMsg:
+		m.services = ServicesSnapshot(msg)
+		return m, nil
+
 	case SystemStats:
 		m.stats = msg
+		if m.history == nil {
+			m.history = make(history)
+		}
+		recordDiskHistory(m.history, msg.Disks)
+		recordNetworkHistory(m.history, msg.Network)
+		recordMemoryHistory(m.history, msg.MemoryDetail)
+		recordPressureHistory(m.history, msg.Pressure)
+		recordPowerHistory(m.history, msg.Power)
+		m.history.record("cpu", msg.CPUUsage)
+		m.history.record("mem", msg.MemoryUsage)
+		m.kernelSeq = recordKernelEvents(m.history, msg.KernelLog, m.kernelSeq, time.Now())
+		recordProcessLogHistory(m.history, msg.ProcessLog)
 		return m, nil
 
 	default:
@@ -137,21 +297,16 @@ func (m model) View() string {
 		return "Loading..."
 	}
 
+	if m.view != viewDashboard {
+		return m.renderView()
+	}
+
 	var s strings.Builder
 
-	// Define styles
-	greenStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
-	yellowStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
-	redStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
-
-	// Helper function to get color style based on percentage
-	getColorStyle := func(percent float64) lipgloss.Style {
-		if percent < 50.0 {
-			return greenStyle
-		} else if percent < 80.0 {
-			return yellowStyle
-		}
-		return redStyle
+	// Alerts above everything else
+	bannerLines := m.renderAlertBanner()
+	for _, line := range bannerLines {
+		s.WriteString(line + "\n")
 	}
 
 	// Main stats bars with labels overlaid in a 2x2 grid
@@ -166,11 +321,17 @@ func (m model) View() string {
 	// Row 1: CPU Usage | GPU Usage
 	cpuStyle := getColorStyle(m.stats.CPUUsage).Underline(true)
 	cpuLabel := "CPU Usage"
+	if m.stats.Container.Active {
+		cpuLabel = fmt.Sprintf("CPU Usage (%s cores)", strconv.FormatFloat(m.stats.Container.Cores, 'f', -1, 64))
+	}
 	cpuPercent := fmt.Sprintf("%5.1f%%", m.stats.CPUUsage)
 	cpuBar := createBarWithText(cpuLabel, cpuPercent, m.stats.CPUUsage, barWidth, cpuStyle)
 
 	gpuStyle := getColorStyle(m.stats.GPUUsage).Underline(true)
 	gpuLabel := "GPU Usage"
+	if m.stats.GPUTemp > 0 {
+		gpuLabel = fmt.Sprintf("GPU Usage %.0f°C", m.stats.GPUTemp)
+	}
 	gpuPercent := fmt.Sprintf("%3.0f%%", m.stats.GPUUsage)
 	gpuBar := createBarWithText(gpuLabel, gpuPercent, m.stats.GPUUsage, barWidth, gpuStyle)
 
@@ -179,6 +340,9 @@ func (m model) View() string {
 	// Row 2: Memory | GPU Memory
 	memStyle := getColorStyle(m.stats.MemoryUsage).Underline(true)
 	memLabel := "Memory"
+	if m.stats.Container.Active {
+		memLabel = "Memory (limit " + formatBytes(float64(m.stats.Container.MemoryLimit)) + ")"
+	}
 	memPercent := fmt.Sprintf("%5.1f%%", m.stats.MemoryUsage)
 	memBar := createBarWithText(memLabel, memPercent, m.stats.MemoryUsage, barWidth, memStyle)
 
@@ -191,66 +355,31 @@ func (m model) View() string {
 
 	s.WriteString("\n")
 
-	// CPU cores with labels overlaid
-	coreCount := len(m.stats.CPUCores)
-	coresPerLine := 4
-	spacingBetweenBars = 2
-
-	availableWidth = m.width
-	// Each bar needs space for label (5 chars) + percentage (6 chars) + some bar space
-	// Total overhead is just spacing between bars since label/percent are inside
-	coreBarWidth := (availableWidth - (coresPerLine-1)*spacingBetweenBars) / coresPerLine
-
-	// Ensure minimum bar width (must fit label + percentage + some bar space)
-	minBarWidth := 15 // "CPU00" (5) + " 100.0%" (7) + 3 bar space
-	if coreBarWidth < minBarWidth {
-		coreBarWidth = minBarWidth
+	// Terminal height, falling back to a reasonable default (24 lines is common)
+	terminalHeight := m.height
+	if terminalHeight == 0 {
+		terminalHeight = 24 // Default terminal height
 	}
 
-	if coreBarWidth < minBarWidth && coresPerLine > 2 {
-		coresPerLine = 2
-		coreBarWidth = (availableWidth - (coresPerLine-1)*spacingBetweenBars) / coresPerLine
-		if coreBarWidth < minBarWidth {
-			coreBarWidth = minBarWidth
-		}
+	// CPU cores: adaptive bars, heatmap, per-node groups or collapsed averages
+	coreLines := m.renderCores(terminalHeight)
+	for _, line := range coreLines {
+		s.WriteString(line + "\n")
 	}
 
-	for i := 0; i < coreCount; i += coresPerLine {
-		var line strings.Builder
-		for j := 0; j < coresPerLine && i+j < coreCount; j++ {
-			coreNum := i + j
-			corePercent := m.stats.CPUCores[coreNum]
-			coreStyle := getColorStyle(corePercent)
-			coreLabel := fmt.Sprintf("CPU%02d", coreNum)
-			corePercentText := fmt.Sprintf("%4.1f%%", corePercent)
-
-			// Create bar with label and percentage overlaid (with underline)
-			coreStyleUnderlined := coreStyle.Underline(true)
-			coreBar := createBarWithText(coreLabel, corePercentText, corePercent, coreBarWidth, coreStyleUnderlined)
+	s.WriteString("\n")
 
-			if j < coresPerLine-1 {
-				line.WriteString(coreBar + "  ")
-			} else {
-				line.WriteString(coreBar)
-			}
-		}
-		s.WriteString(line.String() + "\n")
+	// Optional panels, each followed by a blank line
+	panelLines := m.renderPanels()
+	for _, line := range panelLines {
+		s.WriteString(line + "\n")
 	}
 
-	s.WriteString("\n")
-
 	// Calculate how many lines we've used so far
-	// 2 lines for main stats bars + 1 blank + CPU cores lines + 1 blank + 1 header = 5 + CPU core lines
-	coreLines := (coreCount + coresPerLine - 1) / coresPerLine // Ceiling division
-	linesUsed := 2 + 1 + coreLines + 1 + 1                     // stats + blank + cores + blank + header
+	// Alert banner + 2 lines for main stats bars + 1 blank + CPU core lines + 1 blank + panels + 1 header = 5 + alerts + CPU core lines + panels
+	linesUsed := len(bannerLines) + 2 + 1 + len(coreLines) + 1 + len(panelLines) + 1 // alerts + stats + blank + cores + blank + panels + header
 
 	// Calculate available lines for processes (leave 1 line margin at bottom)
-	// If height is 0 or not set, use a reasonable default (24 lines is common)
-	terminalHeight := m.height
-	if terminalHeight == 0 {
-		terminalHeight = 24 // Default terminal height
-	}
-
 	availableLines := terminalHeight - linesUsed - 1
 	if availableLines < 1 {
 		availableLines = 1 // Always show at least 1 process
@@ -262,36 +391,95 @@ func (m model) View() string {
 		maxProcesses = len(m.stats.Processes)
 	}
 
-	// Process list header
 	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
-	s.WriteString(headerStyle.Render(fmt.Sprintf("%-10s %6s  %5s  %s", "PID", "CPU%", "MEM%", "COMMAND")))
+
+	// Per-container rollup in place of the process list
+	if m.processRollup {
+		rows := renderWorkloadRows(m.stats.Workloads, availableLines, m.width)
+		s.WriteString(headerStyle.Render(rows[0]) + "\n")
+		for _, row := range rows[1:] {
+			s.WriteString(row + "\n")
+		}
+		return s.String()
+	}
+
+	// Leave a line for the note about unreadable I/O counters, shown only
+	// while sorting by I/O since without root it applies to most processes
+	ioNote := m.stats.ProcessIOUnreadable > 0 && (m.processSort == processSortRead || m.processSort == processSortWrite)
+	if ioNote && maxProcesses == availableLines && maxProcesses > 1 {
+		maxProcesses--
+	}
+
+	procs := sortProcesses(m.stats.Processes, m.processSort, m.processReverse)[:maxProcesses]
+	cols := m.processListColumns(procs)
+
+	// Process list header
+	labels := m.processColumnLabels()
+	header := fmt.Sprintf("%-10s %6s  %s", "PID", labels[processSortCPU], m.processMemoryHeader(labels[processSortMemory]))
+	if cols.io {
+		header += fmt.Sprintf("  %8s  %8s", labels[processSortRead], labels[processSortWrite])
+	}
+	if cols.container {
+		header += fmt.Sprintf("  %-*s", containerColumnWidth, "CONTAINER")
+	}
+	s.WriteString(headerStyle.Render(header + "  COMMAND"))
 	s.WriteString("\n")
 
 	// Process list (no underline for percentages)
 	// Calculate available width for COMMAND column
-	commandWidth := m.width - fixedColumnsWidth
+	commandWidth := m.width - m.processFixedWidth(cols)
 	if commandWidth < minCommandWidth {
 		commandWidth = minCommandWidth
 	}
 
-	for i := 0; i < maxProcesses; i++ {
-		proc := m.stats.Processes[i]
+	for _, proc := range procs {
 		cpuStyle := getColorStyle(proc.CPU).Underline(false)
-		memStyle := getColorStyle(float64(proc.Memory)).Underline(false)
 
 		// Truncate command from the left if it's too long
 		truncatedCommand := truncateLeft(proc.Command, commandWidth)
 
-		s.WriteString(fmt.Sprintf("%-10d %s  %s  %s\n",
+		read, write := "-", "-"
+		if proc.HasIO {
+			read, write = formatBytes(proc.ReadBytesPerS)+"/s", formatBytes(proc.WriteBytesPerS)+"/s"
+		}
+
+		row := fmt.Sprintf("%-10d %s  %s",
 			proc.PID,
 			cpuStyle.Render(fmt.Sprintf("%6.1f", proc.CPU)),
-			memStyle.Render(fmt.Sprintf("%5.1f", proc.Memory)),
-			truncatedCommand))
+			m.processMemoryCells(proc))
+		if cols.io {
+			row += fmt.Sprintf("  %8s  %8s", read, write)
+		}
+		if cols.container {
+			row += fmt.Sprintf("  %-*s", containerColumnWidth, truncateLeft(proc.Workload.Label(), containerColumnWidth))
+		}
+		s.WriteString(row + "  " + truncatedCommand + "\n")
+	}
+
+	if ioNote {
+		s.WriteString(fmt.Sprintf("I/O of %d processes is not readable; run as root to see every process\n", m.stats.ProcessIOUnreadable))
 	}
 
 	return s.String()
 }
 
+// Styles for usage thresholds
+var (
+	greenStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	yellowStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	redStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+)
+
+// getColorStyle returns green below 50%, yellow below 80% and red above
+func getColorStyle(percent float64) lipgloss.Style {
+	if percent < 50.0 {
+		return greenStyle
+	} else if percent < 80.0 {
+		return yellowStyle
+	}
+	return redStyle
+}
+
 // truncateLeft truncates a string from the left if it exceeds maxWidth,
 // adding "..." prefix to indicate truncation
 func truncateLeft(s string, maxWidth int) string {
@@ -319,6 +507,25 @@ func truncateLeft(s string, maxWidth int) string {
 	return builder.String()
 }
 
+// truncateRight truncates a string from the right if it exceeds maxWidth,
+// adding "..." suffix to indicate truncation
+func truncateRight(s string, maxWidth int) string {
+	if maxWidth <= 0 {
+		return ""
+	}
+
+	runes := []rune(s)
+	if len(runes) <= maxWidth {
+		return s
+	}
+
+	// Need room for "..." suffix (3 characters)
+	if maxWidth <= 3 {
+		return "..."[:maxWidth]
+	}
+	return string(runes[:maxWidth-3]) + "..."
+}
+
 func createSimpleBar(percent float64, width int, style lipgloss.Style) string {
 	if width <= 0 {
 		return ""
@@ -416,7 +623,7 @@ func createBarWithText(label, percentText string, percent float64, width int, st
 }
 
 func tick() tea.Cmd {
-	return tea.Tick(3*time.Second, func(time.Time) tea.Msg {
+	return tea.Tick(tickInterval, func(time.Time) tea.Msg {
 		return tickMsg{}
 	})
 }
@@ -434,6 +641,11 @@ func collectStats() SystemStats {
 	perCoreCPU, _ := cpu.Percent(time.Second, true)
 	stats.CPUCores = perCoreCPU
 
+	// Per-core time by state (user, system, iowait, steal, ...) since the previous sample
+	stats.CPUTimes = cpuTimesStats.collect()
+	stats.CPUTopology = cachedCPUTopology(sysfsRoot, len(stats.CPUCores))
+	stats.CPUFreq = readCPUFreq(sysfsRoot, len(stats.CPUCores))
+
 	// Calculate average CPU usage from per-core data
 	if len(perCoreCPU) > 0 {
 		var sum float64
@@ -448,175 +660,68 @@ func collectStats() SystemStats {
 	if memInfo != nil {
 		stats.MemoryUsage = memInfo.UsedPercent
 	}
+	stats.MemoryDetail = memStats.collect()
+	stats.MemoryForecast = memoryForecast.update(stats.MemoryDetail, time.Now())
 
-	// GPU stats
-	stats.GPUUsage = getGPUUsage()
-	stats.GPUMemory = getGPUMemory()
-
-	// Process list
-	stats.Processes = getTopProcesses()
-
-	return stats
-}
-
-func getGPUUsage() float64 {
-	switch detectedGPUVendor {
-	case gpuVendorNVIDIA:
-		return getGPUUsageNVIDIA()
-	case gpuVendorAMD:
-		return getGPUUsageAMD()
-	default:
-		return 0.0
-	}
-}
-
-func getGPUUsageNVIDIA() float64 {
-	cmd := exec.Command("nvidia-smi", "--query-gpu=utilization.gpu", "--format=csv,noheader,nounits")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0.0
+	// In the container perspective the CPU and Memory bars are relative to
+	// sysmon's own cgroup limits rather than the whole host
+	limits := readCgroupLimits(procfsRoot, cgroupRoot())
+	coresUsed := cgroupCPUStats.sample(limits.CPUUsageUsec, time.Now())
+	container, cpuPercent, memPercent := containerStats(perspective, limits, coresUsed, len(perCoreCPU), stats.MemoryDetail.Total)
+	if container.Active {
+		stats.Container = container
+		stats.CPUUsage = cpuPercent
+		stats.MemoryUsage = memPercent
 	}
 
-	usageStr := strings.TrimSpace(string(output))
-	usage, err := strconv.ParseFloat(usageStr, 64)
-	if err != nil {
-		return 0.0
-	}
-
-	return usage
-}
-
-func getGPUUsageAMD() float64 {
-	cmd := exec.Command("rocm-smi", "--showuse")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0.0
-	}
-
-	// Parse rocm-smi output
-	// rocm-smi --showuse output format:
-	// ========================= ROCm System Management Interface =========================
-	// ================================ GPU use ================================
-	// GPU[0]		: GPU use (%): 25
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		// Look for GPU[0] specifically at the start and check for "GPU use (%)"
-		if strings.HasPrefix(strings.TrimSpace(line), "GPU[0]") && strings.Contains(line, "GPU use (%)") {
-			// Extract value after the last colon
-			if valueStr, ok := extractValueAfterLastColon(line); ok {
-				if usage, err := strconv.ParseFloat(valueStr, 64); err == nil {
-					return usage
-				}
-			}
-		}
-	}
+	// Pressure stall information, system-wide and for the configured cgroups
+	stats.Pressure = getPressure(procfsRoot, cgroupRoot(), pressureCgroups)
 
-	return 0.0
-}
+	// hwmon and thermal zone temperatures and fans
+	stats.Sensors = getSensors(sysfsRoot)
 
-// extractValueAfterLastColon extracts and trims the string after the last colon in a line
-func extractValueAfterLastColon(line string) (string, bool) {
-	lastColonIdx := strings.LastIndex(line, ":")
-	if lastColonIdx == -1 || lastColonIdx+1 > len(line) {
-		return "", false
+	// GPU stats
+	refreshGPUBackends(time.Now())
+	stats.GPUUsage = getGPUUsage()
+	stats.GPUMemory = getGPUMemory()
+	stats.GPUTemp = getGPUTemp()
+	if alertRules.usesGPUDevices() {
+		stats.GPUs = getGPUDevices()
 	}
-	return strings.TrimSpace(line[lastColonIdx+1:]), true
-}
 
-func getGPUMemory() float64 {
-	switch detectedGPUVendor {
-	case gpuVendorNVIDIA:
-		return getGPUMemoryNVIDIA()
-	case gpuVendorAMD:
-		return getGPUMemoryAMD()
-	default:
-		return 0.0
-	}
-}
+	// RAPL and GPU power since the previous sample
+	stats.Power = powerStats.collect(sysfsRoot)
 
-func getGPUMemoryNVIDIA() float64 {
-	cmd := exec.Command("nvidia-smi", "--query-gpu=memory.used,memory.total", "--format=csv,noheader,nounits")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0.0
-	}
+	// Process list and per-container totals
+	stats.Processes, stats.Workloads, stats.ProcessIOUnreadable = getTopProcesses()
+	stats.Leaks = leakStats.update(stats.Processes, stats.MemoryDetail.Available, time.Now())
+	stats.ProcessLog = processLifecycle.update(stats.Processes, readForks(procfsRoot), time.Now())
 
-	parts := strings.Split(strings.TrimSpace(string(output)), ", ")
-	if len(parts) != 2 {
-		return 0.0
-	}
+	// OOM kills, segfaults, hung tasks and throttling from the kernel log
+	stats.KernelLog = kernelLog.snapshot()
 
-	used, err1 := strconv.ParseFloat(parts[0], 64)
-	total, err2 := strconv.ParseFloat(parts[1], 64)
-	if err1 != nil || err2 != nil || total == 0 {
-		return 0.0
-	}
+	// Disk I/O rates since the previous sample
+	stats.Disks = diskStats.collect()
 
-	return (used / total) * 100.0
-}
+	// Filesystem space and inode usage
+	stats.Filesystems = getFilesystems(fsFilter)
 
-func getGPUMemoryAMD() float64 {
-	cmd := exec.Command("rocm-smi", "--showmeminfo", "vram")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0.0
-	}
-
-	// Parse rocm-smi output
-	// rocm-smi --showmeminfo vram output format:
-	// ========================= ROCm System Management Interface =========================
-	// ================================ VRAM Total Memory (B) ================================
-	// GPU[0]		: VRAM Total Memory (B): 17163091968
-	// ================================ VRAM Total Used Memory (B) ================================
-	// GPU[0]		: VRAM Total Used Memory (B): 1234567890
-	var totalMem, usedMem float64
-	var foundTotal, foundUsed bool
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
-
-		// Look for GPU[0] specifically at the start
-		if strings.HasPrefix(trimmedLine, "GPU[0]") {
-			if strings.Contains(line, "VRAM Total Memory (B)") && !strings.Contains(line, "Used") {
-				// Extract value after the last colon
-				if totalStr, ok := extractValueAfterLastColon(line); ok {
-					if total, err := strconv.ParseFloat(totalStr, 64); err == nil {
-						totalMem = total
-						foundTotal = true
-						// If we've found both values, we can stop searching
-						if foundUsed {
-							break
-						}
-					}
-				}
-			} else if strings.Contains(line, "VRAM Total Used Memory (B)") {
-				// Extract value after the last colon
-				if usedStr, ok := extractValueAfterLastColon(line); ok {
-					if used, err := strconv.ParseFloat(usedStr, 64); err == nil {
-						usedMem = used
-						foundUsed = true
-						// If we've found both values, we can stop searching
-						if foundTotal {
-							break
-						}
-					}
-				}
-			}
-		}
-	}
+	// Network interface rates since the previous sample
+	stats.Network = netStats.collect()
 
-	// Only calculate percentage if we successfully parsed both values
-	if foundTotal && foundUsed && totalMem > 0 {
-		return (usedMem / totalMem) * 100.0
-	}
+	// Alert rules see the complete sample
+	stats.Alerts = alertRules.evaluate(stats, time.Now())
 
-	return 0.0
+	return stats
 }
 
-func getTopProcesses() []ProcessInfo {
+// getTopProcesses returns the busiest processes and the usage of all
+// processes rolled up by workload
+func getTopProcesses() ([]ProcessInfo, []WorkloadUsage, int) {
 	processes, _ := process.Processes()
 	var procInfos []ProcessInfo
+	counters := make(map[int32]procIOCounters)
+	ioUnreadable := 0
 
 	for _, p := range processes {
 		cpuPercent, err := p.CPUPercent()
@@ -629,34 +734,54 @@ func getTopProcesses() []ProcessInfo {
 			continue
 		}
 
-		if cpuPercent == 0 {
-			continue
-		}
-
 		exe, err := p.Exe()
 		if err != nil {
 			name, _ := p.Name()
 			exe = name
 		}
 
-		procInfos = append(procInfos, ProcessInfo{
-			PID:     p.Pid,
-			CPU:     cpuPercent,
-			Memory:  memPercent,
-			Command: exe,
-		})
+		created, _ := p.CreateTime()
+
+		// /proc/<pid>/io is only readable for our own processes unless root
+		if io, err := p.IOCounters(); err == nil {
+			counters[p.Pid] = procIOCounters{created: created, readBytes: io.ReadBytes, writeBytes: io.WriteBytes}
+		} else {
+			ioUnreadable++
+		}
+
+		info := ProcessInfo{
+			PID:      p.Pid,
+			CPU:      cpuPercent,
+			Memory:   memPercent,
+			Command:  exe,
+			Workload: readWorkload(procfsRoot, p.Pid),
+			Created:  created,
+			OOMScore: readOOMScore(procfsRoot, p.Pid),
+		}
+		if mi, err := p.MemoryInfoEx(); err == nil {
+			info.RSS, info.VMS, info.Shared = mi.RSS, mi.VMS, mi.Shared
+		}
+		if readSmaps {
+			if smaps, err := readSmapsRollup(procfsRoot, p.Pid); err == nil {
+				info.PSS, info.USS, info.Swap, info.HasSmaps = smaps.PSS, smaps.USS, smaps.Swap, true
+			}
+		}
+		procInfos = append(procInfos, info)
 	}
 
-	sort.Slice(procInfos, func(i, j int) bool {
+	rates := processIOStats.sample(counters, time.Now())
+	for i := range procInfos {
+		pid := procInfos[i].PID
+		_, procInfos[i].HasIO = counters[pid]
+		procInfos[i].ReadBytesPerS = rates[pid].read
+		procInfos[i].WriteBytesPerS = rates[pid].write
+	}
+
+	// Every process is kept so the list can be sorted by any column; the
+	// view shows as many as fit
+	sort.SliceStable(procInfos, func(i, j int) bool {
 		return procInfos[i].CPU > procInfos[j].CPU
 	})
 
-	// Return up to 100 processes (enough for most terminal sizes)
-	// The view will limit further based on available height
-	maxToCollect := 100
-	if len(procInfos) > maxToCollect {
-		procInfos = procInfos[:maxToCollect]
-	}
-
-	return procInfos
+	return procInfos, rollupWorkloads(procInfos), ioUnreadable
 }
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// MemoryDetail breaks system memory down the way /proc/meminfo does. All
+// sizes are in bytes.
+type MemoryDetail struct {
+	Total           uint64
+	Used            uint64
+	Free            uint64
+	Buffers         uint64
+	Cached          uint64
+	Shared          uint64
+	Slab            uint64
+	SlabReclaimable uint64
+	Dirty           uint64
+	Writeback       uint64
+	Available       uint64
+
+	SwapTotal uint64
+	SwapUsed  uint64
+	// Swap traffic since the previous sample
+	SwapInBytesPerS  float64
+	SwapOutBytesPerS float64
+}
+
+// Colors for the segments of the memory bar, following htop
+var (
+	memUsedStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	memBuffersStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("4"))
+	memCacheStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+)
+
+// memCollector keeps the swap counters needed to turn them into rates
+type memCollector struct {
+	mu       sync.Mutex
+	prevIn   uint64
+	prevOut  uint64
+	prevTime time.Time
+}
+
+var memStats = &memCollector{}
+
+// collect reads the memory breakdown and swap rates since the previous call
+func (c *memCollector) collect() MemoryDetail {
+	var detail MemoryDetail
+
+	if vm, err := mem.VirtualMemory(); err == nil {
+		detail = MemoryDetail{
+			Total:           vm.Total,
+			Used:            vm.Used,
+			Free:            vm.Free,
+			Buffers:         vm.Buffers,
+			Cached:          vm.Cached,
+			Shared:          vm.Shared,
+			Slab:            vm.Slab,
+			SlabReclaimable: vm.Sreclaimable,
+			Dirty:           vm.Dirty,
+			Writeback:       vm.WriteBack,
+			Available:       vm.Available,
+		}
+	}
+
+	if swap, err := mem.SwapMemory(); err == nil {
+		detail.SwapTotal = swap.Total
+		detail.SwapUsed = swap.Used
+		detail.SwapInBytesPerS, detail.SwapOutBytesPerS = c.swapRates(swap.Sin, swap.Sout, time.Now())
+	}
+
+	return detail
+}
+
+// swapRates returns swap-in and swap-out bytes per second since the previous
+// call; the first call only records a baseline
+func (c *memCollector) swapRates(sin, sout uint64, now time.Time) (float64, float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prevIn, prevOut, prevTime := c.prevIn, c.prevOut, c.prevTime
+	c.prevIn, c.prevOut, c.prevTime = sin, sout, now
+	if prevTime.IsZero() {
+		return 0, 0
+	}
+
+	elapsed := now.Sub(prevTime).Seconds()
+	if elapsed <= 0 {
+		return 0, 0
+	}
+	return counterDelta(sin, prevIn) / elapsed, counterDelta(sout, prevOut) / elapsed
+}
+
+// recordMemoryHistory appends the swap traffic samples used by the sparkline
+func recordMemoryHistory(h history, detail MemoryDetail) {
+	h.record("swap:io", detail.SwapInBytesPerS+detail.SwapOutBytesPerS)
+}
+
+// percentOf returns part as a percentage of total
+func percentOf(part, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// renderMemoryPanel renders a stacked used/buffers/cache bar with a legend,
+// the remaining meminfo counters, the exhaustion forecast and the processes
+// the OOM killer would pick first, and a swap bar with traffic rates
+func renderMemoryPanel(m model) []string {
+	d := m.stats.MemoryDetail
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	lines := []string{headerStyle.Render("MEMORY")}
+
+	if d.Total == 0 {
+		return append(lines, "(memory information unavailable)")
+	}
+
+	barWidth := m.width - 6
+	if barWidth < 20 {
+		barWidth = 20
+	}
+	lines = append(lines, "RAM   "+createStackedBar([]barSegment{
+		{percentOf(d.Used, d.Total), memUsedStyle},
+		{percentOf(d.Buffers, d.Total), memBuffersStyle},
+		{percentOf(d.Cached, d.Total), memCacheStyle},
+	}, barWidth))
+
+	availableStyle := getColorStyle(100 - percentOf(d.Available, d.Total))
+	lines = append(lines, fmt.Sprintf("      %s used %s  %s buffers %s  %s cache %s  ░ free %s  of %s  available %s",
+		memUsedStyle.Render("█"), formatBytes(float64(d.Used)),
+		memBuffersStyle.Render("█"), formatBytes(float64(d.Buffers)),
+		memCacheStyle.Render("█"), formatBytes(float64(d.Cached)),
+		formatBytes(float64(d.Free)), formatBytes(float64(d.Total)),
+		availableStyle.Render(fmt.Sprintf("%s (%.0f%%)", formatBytes(float64(d.Available)), percentOf(d.Available, d.Total)))))
+
+	lines = append(lines, fmt.Sprintf("      shared %s  slab %s (reclaimable %s)  dirty %s  writeback %s",
+		formatBytes(float64(d.Shared)), formatBytes(float64(d.Slab)), formatBytes(float64(d.SlabReclaimable)),
+		formatBytes(float64(d.Dirty)), formatBytes(float64(d.Writeback))))
+
+	lines = append(lines, "Trend "+forecastLine(m.stats.MemoryForecast))
+	if oom := oomLine(m.stats.Processes); oom != "" {
+		lines = append(lines, "OOM   "+truncateLeft(oom, m.width-6))
+	}
+
+	if d.SwapTotal == 0 {
+		return append(lines, "Swap  (none)")
+	}
+
+	swapPercent := percentOf(d.SwapUsed, d.SwapTotal)
+	swapText := fmt.Sprintf("%s / %s", formatBytes(float64(d.SwapUsed)), formatBytes(float64(d.SwapTotal)))
+	rates := fmt.Sprintf("  in %9s  out %9s", formatBytes(d.SwapInBytesPerS)+"/s", formatBytes(d.SwapOutBytesPerS)+"/s")
+	if d.SwapInBytesPerS+d.SwapOutBytesPerS > 0 {
+		rates = yellowStyle.Render(rates)
+	}
+
+	swapBarWidth := 30
+	sparkWidth := m.width - 6 - swapBarWidth - 31
+	line := "Swap  " + createBarWithText("", swapText, swapPercent, swapBarWidth, getColorStyle(swapPercent)) + rates
+	if sparkWidth > 0 {
+		line += "  " + sparkline(m.history["swap:io"].last(sparkWidth), sparkWidth, 0)
+	}
+	return append(lines, line)
+}
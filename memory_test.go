@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemCollectorSwapRates(t *testing.T) {
+	c := &memCollector{}
+	start := time.Unix(1000, 0)
+
+	if in, out := c.swapRates(4096, 8192, start); in != 0 || out != 0 {
+		t.Errorf("first swapRates() = %v, %v; expected a zero baseline", in, out)
+	}
+	in, out := c.swapRates(4096+3*4096, 8192+6*4096, start.Add(3*time.Second))
+	if in != 4096 || out != 8192 {
+		t.Errorf("swapRates() = %v, %v; expected 4096, 8192 bytes/s", in, out)
+	}
+}
+
+func TestMemoryPanelRenders(t *testing.T) {
+	const gib = 1 << 30
+	m := model{
+		width:   120,
+		height:  40,
+		panels:  map[string]bool{"memory": true},
+		history: history{},
+		stats: SystemStats{
+			MemoryDetail: MemoryDetail{
+				Total: 16 * gib, Used: 4 * gib, Buffers: gib / 2, Cached: 10 * gib, Free: 1536 << 20,
+				Shared: gib, Slab: gib, SlabReclaimable: gib / 2, Dirty: 64 << 20,
+				Available: 12 * gib, SwapTotal: 8 * gib, SwapUsed: 2 * gib, SwapOutBytesPerS: 4096,
+			},
+		},
+	}
+
+	view := stripAnsiCodes(m.View())
+	for _, want := range []string{"used 4.0G", "buffers 512.0M", "cache 10.0G", "free 1.5G", "available 12.0G (75%)", "dirty 64.0M", "2.0G / 8.0G", "out    4.0K/s"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected memory panel to contain %q:\n%s", want, view)
+		}
+	}
+}
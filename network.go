@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// NetworkStats holds per-interface rates computed between two samples
+type NetworkStats struct {
+	Name          string
+	RxBytesPerS   float64
+	TxBytesPerS   float64
+	RxPacketsPerS float64
+	TxPacketsPerS float64
+	ErrorsPerS    float64
+	DropsPerS     float64
+	// SpeedMbps is the negotiated link speed, or 0 when unknown
+	SpeedMbps float64
+	// Virtual marks loopback, veth and container bridge interfaces
+	Virtual bool
+}
+
+// Interface name patterns hidden unless virtual interfaces are toggled on
+var virtualInterfacePatterns = []string{"lo", "veth*", "docker*", "br-*", "virbr*", "cni*", "flannel*"}
+
+// netCollector turns cumulative interface counters into rates between ticks
+type netCollector struct {
+	mu       sync.Mutex
+	prev     map[string]net.IOCountersStat
+	prevTime time.Time
+}
+
+var netStats = &netCollector{}
+
+// collect samples the kernel counters and returns rates since the previous call
+func (c *netCollector) collect() []NetworkStats {
+	counters, err := net.IOCounters(true)
+	if err != nil {
+		return nil
+	}
+	return c.sample(counters, time.Now(), sysfsRoot)
+}
+
+// sample computes rates from counters taken at now. The first call only
+// records a baseline and returns nil.
+func (c *netCollector) sample(counters []net.IOCountersStat, now time.Time, root string) []NetworkStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := make(map[string]net.IOCountersStat, len(counters))
+	for _, cur := range counters {
+		current[cur.Name] = cur
+	}
+
+	prev, prevTime := c.prev, c.prevTime
+	c.prev, c.prevTime = current, now
+	if prev == nil {
+		return nil
+	}
+
+	elapsed := now.Sub(prevTime).Seconds()
+	if elapsed <= 0 {
+		return nil
+	}
+
+	var result []NetworkStats
+	for name, cur := range current {
+		old, ok := prev[name]
+		if !ok {
+			continue
+		}
+
+		errors := counterDelta(cur.Errin, old.Errin) + counterDelta(cur.Errout, old.Errout)
+		drops := counterDelta(cur.Dropin, old.Dropin) + counterDelta(cur.Dropout, old.Dropout)
+		result = append(result, NetworkStats{
+			Name:          name,
+			RxBytesPerS:   counterDelta(cur.BytesRecv, old.BytesRecv) / elapsed,
+			TxBytesPerS:   counterDelta(cur.BytesSent, old.BytesSent) / elapsed,
+			RxPacketsPerS: counterDelta(cur.PacketsRecv, old.PacketsRecv) / elapsed,
+			TxPacketsPerS: counterDelta(cur.PacketsSent, old.PacketsSent) / elapsed,
+			ErrorsPerS:    errors / elapsed,
+			DropsPerS:     drops / elapsed,
+			SpeedMbps:     readLinkSpeed(root, name),
+			Virtual:       isVirtualInterface(name),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// readLinkSpeed reads <root>/class/net/<iface>/speed in Mbit/s. Interfaces
+// without a carrier or without a fixed speed report -1 or fail to read.
+func readLinkSpeed(root, iface string) float64 {
+	speed, err := readSysfsFloat(filepath.Join(root, "class", "net", iface, "speed"))
+	if err != nil || speed <= 0 {
+		return 0
+	}
+	return speed
+}
+
+func isVirtualInterface(name string) bool {
+	for _, pattern := range virtualInterfacePatterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// recordNetworkHistory appends the receive and transmit samples used by the sparklines
+func recordNetworkHistory(h history, ifaces []NetworkStats) {
+	for _, n := range ifaces {
+		h.record("net:"+n.Name+":rx", n.RxBytesPerS)
+		h.record("net:"+n.Name+":tx", n.TxBytesPerS)
+	}
+}
+
+// linkUtilization returns bytesPerS as a percentage of the link speed. When the
+// speed is unknown the peak of the interface's history is used as the scale.
+func linkUtilization(bytesPerS, speedMbps float64, hist *series) float64 {
+	if speedMbps > 0 {
+		return bytesPerS * 8 / (speedMbps * 1e6) * 100
+	}
+	var peak float64
+	for _, v := range hist.last(historyLength) {
+		if v > peak {
+			peak = v
+		}
+	}
+	if peak == 0 {
+		return 0
+	}
+	return bytesPerS / peak * 100
+}
+
+// renderNetworkPanel renders a receive and a transmit line per interface, each
+// with a bar scaled to the link speed, packet/error rates and a sparkline
+func renderNetworkPanel(m model) []string {
+	// Fixed columns: IFACE (12) + DIR (2) + bar (24) + RATE (9) + PKT/s (8) + ERR/s (6) + DROP/s (6) + LINK (7) + spacing (9) = 83
+	sparkWidth := m.width - 83
+
+	header := fmt.Sprintf("%-12s %-2s %-24s %9s %8s %6s %6s %7s",
+		"IFACE", "", "UTIL", "RATE", "PKT/s", "ERR/s", "DROP/s", "LINK")
+	if sparkWidth > 0 {
+		header += "  " + truncateLeft("HISTORY", sparkWidth)
+	}
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	lines := []string{headerStyle.Render(header)}
+
+	hidden := 0
+	for _, n := range m.stats.Network {
+		if n.Virtual && !m.showVirtualIfaces {
+			hidden++
+			continue
+		}
+
+		link := "?"
+		if n.SpeedMbps >= 1000 {
+			link = fmt.Sprintf("%.0fG", n.SpeedMbps/1000)
+		} else if n.SpeedMbps > 0 {
+			link = fmt.Sprintf("%.0fM", n.SpeedMbps)
+		}
+
+		rxErrs := fmt.Sprintf("%6.0f", n.ErrorsPerS)
+		if n.ErrorsPerS > 0 {
+			rxErrs = redStyle.Render(rxErrs)
+		}
+		rxDrops := fmt.Sprintf("%6.0f", n.DropsPerS)
+		if n.DropsPerS > 0 {
+			rxDrops = yellowStyle.Render(rxDrops)
+		}
+
+		lines = append(lines,
+			m.networkRow(truncateLeft(n.Name, 12), "rx", n.Name, n.RxBytesPerS, n.RxPacketsPerS, n.SpeedMbps, rxErrs, rxDrops, link, sparkWidth),
+			m.networkRow("", "tx", n.Name, n.TxBytesPerS, n.TxPacketsPerS, n.SpeedMbps, "", "", "", sparkWidth))
+	}
+
+	if hidden > 0 {
+		lines = append(lines, fmt.Sprintf("(%d virtual interfaces hidden, press v to show)", hidden))
+	}
+	return lines
+}
+
+// networkRow renders one direction of an interface. Errors and drops are
+// totals for both directions, so they are only passed for the rx row.
+func (m model) networkRow(label, dir, iface string, rate, packets, speedMbps float64, errs, drops, link string, sparkWidth int) string {
+	hist := m.history["net:"+iface+":"+dir]
+	util := linkUtilization(rate, speedMbps, hist)
+	bar := createBarWithText("", fmt.Sprintf("%.0f%%", util), util, 24, getColorStyle(util))
+
+	line := fmt.Sprintf("%-12s %-2s %s %9s %8.0f %6s %6s %7s",
+		label, dir, bar, formatBytes(rate)+"/s", packets, errs, drops, link)
+	if sparkWidth > 0 {
+		line += "  " + sparkline(hist.last(sparkWidth), sparkWidth, 0)
+	}
+	return line
+}
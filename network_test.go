@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestNetCollectorSample(t *testing.T) {
+	root := t.TempDir()
+	speedDir := filepath.Join(root, "class", "net", "eth0")
+	if err := os.MkdirAll(speedDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(speedDir, "speed"), []byte("1000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &netCollector{}
+	start := time.Unix(1000, 0)
+	c.sample([]net.IOCountersStat{
+		{Name: "eth0", BytesRecv: 1000, BytesSent: 500, PacketsRecv: 10, PacketsSent: 5},
+		{Name: "lo", BytesRecv: 0},
+	}, start, root)
+
+	got := c.sample([]net.IOCountersStat{
+		{Name: "eth0", BytesRecv: 5000, BytesSent: 2500, PacketsRecv: 50, PacketsSent: 25, Errin: 2, Dropout: 4},
+		{Name: "lo", BytesRecv: 100},
+	}, start.Add(2*time.Second), root)
+
+	if len(got) != 2 || got[0].Name != "eth0" || got[1].Name != "lo" {
+		t.Fatalf("sample() = %+v; expected eth0 and lo", got)
+	}
+
+	eth := got[0]
+	if eth.RxBytesPerS != 2000 || eth.TxBytesPerS != 1000 || eth.RxPacketsPerS != 20 || eth.TxPacketsPerS != 10 {
+		t.Errorf("eth0 rates = %+v", eth)
+	}
+	if eth.ErrorsPerS != 1 || eth.DropsPerS != 2 {
+		t.Errorf("eth0 errors/drops = %v/%v; expected 1/2", eth.ErrorsPerS, eth.DropsPerS)
+	}
+	if eth.SpeedMbps != 1000 || eth.Virtual {
+		t.Errorf("eth0 speed=%v virtual=%v; expected 1000 and physical", eth.SpeedMbps, eth.Virtual)
+	}
+	if lo := got[1]; lo.SpeedMbps != 0 || !lo.Virtual {
+		t.Errorf("lo speed=%v virtual=%v; expected unknown speed and virtual", lo.SpeedMbps, lo.Virtual)
+	}
+}
+
+func TestIsVirtualInterface(t *testing.T) {
+	for name, want := range map[string]bool{
+		"lo": true, "veth1a2b3c": true, "docker0": true, "br-4f2a": true,
+		"eth0": false, "enp3s0": false, "wlan0": false, "bond0": false,
+	} {
+		if got := isVirtualInterface(name); got != want {
+			t.Errorf("isVirtualInterface(%q) = %v; expected %v", name, got, want)
+		}
+	}
+}
+
+func TestLinkUtilization(t *testing.T) {
+	// 62.5 MB/s on a gigabit link is half the line rate
+	if got := linkUtilization(62.5e6, 1000, nil); math.Abs(got-50) > 0.001 {
+		t.Errorf("linkUtilization on 1G = %v; expected 50", got)
+	}
+
+	// Without a known speed the history peak is the scale
+	hist := &series{values: []float64{100, 400, 200}}
+	if got := linkUtilization(100, 0, hist); got != 25 {
+		t.Errorf("linkUtilization against history = %v; expected 25", got)
+	}
+}
+
+func TestNetworkPanelHidesVirtualInterfaces(t *testing.T) {
+	m := model{
+		width:   120,
+		height:  40,
+		panels:  map[string]bool{"network": true},
+		history: history{},
+		stats: SystemStats{
+			Network: []NetworkStats{
+				{Name: "eth0", RxBytesPerS: 2 << 20, SpeedMbps: 10000},
+				{Name: "docker0", RxBytesPerS: 1 << 10, Virtual: true},
+			},
+		},
+	}
+
+	view := stripAnsiCodes(m.View())
+	if !strings.Contains(view, "eth0") || !strings.Contains(view, "10G") || !strings.Contains(view, "2.0M/s") {
+		t.Errorf("expected eth0 row in network panel:\n%s", view)
+	}
+	if strings.Contains(view, "docker0") || !strings.Contains(view, "1 virtual interfaces hidden") {
+		t.Errorf("expected docker0 to be hidden:\n%s", view)
+	}
+
+	m.showVirtualIfaces = true
+	if view := stripAnsiCodes(m.View()); !strings.Contains(view, "docker0") {
+		t.Errorf("expected docker0 once virtual interfaces are shown:\n%s", view)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+// panel is an optional dashboard section shown between the CPU cores and the
+// process list. Each panel is toggled with its key.
+type panel struct {
+	name   string
+	key    string
+	render func(m model) []string
+}
+
+// panels lists the available panels in display order
+var panels = []panel{
+	{name: "memory", key: "m", render: renderMemoryPanel},
+	{name: "leaks", key: "l", render: renderLeaksPanel},
+	{name: "pressure", key: "p", render: renderPressurePanel},
+	{name: "sensors", key: "t", render: renderSensorsPanel},
+	{name: "power", key: "w", render: renderPowerPanel},
+	{name: "disk", key: "d", render: renderDiskPanel},
+	{name: "filesystems", key: "f", render: renderFilesystemPanel},
+	{name: "network", key: "n", render: renderNetworkPanel},
+}
+
+// panelNames returns the names accepted by the "panels" config option
+func panelNames() []string {
+	names := make([]string, len(panels))
+	for i, p := range panels {
+		names[i] = p.name
+	}
+	return names
+}
+
+// renderPanels renders the enabled panels, each followed by a blank line
+func (m model) renderPanels() []string {
+	var lines []string
+	for _, p := range panels {
+		if !m.panels[p.name] {
+			continue
+		}
+		lines = append(lines, p.render(m)...)
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// togglePanel flips the panel bound to key, reporting whether one matched
+func (m model) togglePanel(key string) bool {
+	for _, p := range panels {
+		if p.key == key {
+			m.panels[p.name] = !m.panels[p.name]
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// raplReading is one powercap zone's cumulative energy counter
+type raplReading struct {
+	// Zone is the powercap directory name, e.g. "intel-rapl:0:1"
+	Zone string
+	// Name is the domain, e.g. "package-0", "core", "uncore", "dram", "psys"
+	Name     string
+	EnergyUJ uint64
+	// MaxEnergyUJ is where the counter wraps around to zero
+	MaxEnergyUJ uint64
+}
+
+// PowerDomain is the power drawn by one RAPL domain
+type PowerDomain struct {
+	Zone string
+	// Name is prefixed with its package for subzones, e.g. "package-0/dram"
+	Name  string
+	Watts float64
+	// SessionJoules is the energy used since sysmon started
+	SessionJoules float64
+}
+
+// PowerStats combines RAPL domains with the GPU draw
+type PowerStats struct {
+	Domains []PowerDomain
+	// Unreadable counts zones whose energy_uj couldn't be read; it is
+	// root-only on kernels with the PLATYPUS mitigation
+	Unreadable       int
+	GPUWatts         float64
+	HasGPU           bool
+	GPUSessionJoules float64
+	// Since is when the session energy started accumulating
+	Since time.Time
+}
+
+// TotalWatts is the CPU packages, DRAM and GPUs combined. psys already
+// includes the packages and core/uncore are part of them, so those are left
+// out to avoid double counting.
+func (p PowerStats) TotalWatts() float64 {
+	total := p.GPUWatts
+	for _, d := range p.Domains {
+		if countsTowardTotal(d) {
+			total += d.Watts
+		}
+	}
+	return total
+}
+
+// TotalSessionJoules is the session energy of the domains in TotalWatts
+func (p PowerStats) TotalSessionJoules() float64 {
+	total := p.GPUSessionJoules
+	for _, d := range p.Domains {
+		if countsTowardTotal(d) {
+			total += d.SessionJoules
+		}
+	}
+	return total
+}
+
+// countsTowardTotal reports whether a domain is a package or DRAM
+func countsTowardTotal(d PowerDomain) bool {
+	name := d.Name[strings.LastIndex(d.Name, "/")+1:]
+	return strings.HasPrefix(name, "package") || name == "dram"
+}
+
+// readRAPL reads the intel-rapl zones under <root>/class/powercap, which AMD
+// CPUs also expose. The intel-rapl-mmio zones duplicate the MSR package
+// counters and are skipped. Zones whose counter can't be read are counted
+// in unreadable.
+func readRAPL(root string) (readings []raplReading, unreadable int) {
+	zones, _ := filepath.Glob(filepath.Join(root, "class", "powercap", "intel-rapl:*"))
+	sort.Strings(zones)
+
+	for _, zone := range zones {
+		name := readSysfsString(filepath.Join(zone, "name"))
+		if name == "" {
+			continue
+		}
+		energy, err := strconv.ParseUint(readSysfsString(filepath.Join(zone, "energy_uj")), 10, 64)
+		if err != nil {
+			unreadable++
+			continue
+		}
+		// Without the counter's range a wrapped counter can't be accounted
+		// for; a zero range makes sample skip it
+		maxEnergy, _ := strconv.ParseUint(readSysfsString(filepath.Join(zone, "max_energy_range_uj")), 10, 64)
+		readings = append(readings, raplReading{
+			Zone:        filepath.Base(zone),
+			Name:        name,
+			EnergyUJ:    energy,
+			MaxEnergyUJ: maxEnergy,
+		})
+	}
+	return readings, unreadable
+}
+
+// powerCollector turns energy counters into watts and accumulates the
+// session energy
+type powerCollector struct {
+	mu        sync.Mutex
+	prev      map[string]uint64
+	prevTime  time.Time
+	start     time.Time
+	session   map[string]float64
+	gpuJoules float64
+}
+
+var powerStats = &powerCollector{}
+
+// collect reads the RAPL counters and GPU power and returns the draw since
+// the previous call
+func (c *powerCollector) collect(root string) PowerStats {
+	readings, unreadable := readRAPL(root)
+	gpuWatts, hasGPU := getGPUPower()
+	stats := c.sample(readings, gpuWatts, hasGPU, time.Now())
+	stats.Unreadable = unreadable
+	return stats
+}
+
+// sample computes per-domain watts from the energy consumed since the
+// previous call, allowing for counter wraparound; domains whose counter
+// went backwards without a known range are left out of that sample. The
+// first call only records a baseline for RAPL; the GPU, which reports watts
+// directly, starts accumulating energy from the second call.
+func (c *powerCollector) sample(readings []raplReading, gpuWatts float64, hasGPU bool, now time.Time) PowerStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.session == nil {
+		c.session = make(map[string]float64)
+		c.start = now
+	}
+
+	prev, prevTime := c.prev, c.prevTime
+	c.prev = make(map[string]uint64, len(readings))
+	for _, r := range readings {
+		c.prev[r.Zone] = r.EnergyUJ
+	}
+	c.prevTime = now
+
+	stats := PowerStats{GPUWatts: gpuWatts, HasGPU: hasGPU, Since: c.start}
+	if prevTime.IsZero() {
+		return stats
+	}
+	elapsed := now.Sub(prevTime).Seconds()
+	if elapsed <= 0 {
+		return stats
+	}
+
+	if hasGPU {
+		c.gpuJoules += gpuWatts * elapsed
+	}
+	stats.GPUSessionJoules = c.gpuJoules
+
+	packages := make(map[string]string)
+	for _, r := range readings {
+		if strings.Count(r.Zone, ":") == 1 {
+			packages[r.Zone] = r.Name
+		}
+	}
+
+	for _, r := range readings {
+		p, ok := prev[r.Zone]
+		if !ok {
+			continue
+		}
+		delta := r.EnergyUJ - p
+		if r.EnergyUJ < p {
+			// A counter that went backwards past an unknown or smaller
+			// range was reset rather than wrapped
+			if r.MaxEnergyUJ == 0 || r.MaxEnergyUJ < p {
+				continue
+			}
+			delta = r.MaxEnergyUJ - p + r.EnergyUJ
+		}
+		joules := float64(delta) / 1e6
+		c.session[r.Zone] += joules
+
+		name := r.Name
+		if parent := r.Zone[:strings.LastIndex(r.Zone, ":")]; strings.Count(r.Zone, ":") > 1 && packages[parent] != "" {
+			name = packages[parent] + "/" + r.Name
+		}
+		stats.Domains = append(stats.Domains, PowerDomain{
+			Zone:          r.Zone,
+			Name:          name,
+			Watts:         joules / elapsed,
+			SessionJoules: c.session[r.Zone],
+		})
+	}
+	return stats
+}
+
+// recordPowerHistory appends the watt samples used by the sparklines
+func recordPowerHistory(h history, p PowerStats) {
+	for _, d := range p.Domains {
+		h.record("power:"+d.Zone, d.Watts)
+	}
+	if p.HasGPU {
+		h.record("power:gpu", p.GPUWatts)
+	}
+	if len(p.Domains) > 0 || p.HasGPU {
+		h.record("power:total", p.TotalWatts())
+	}
+}
+
+// formatEnergy formats joules as Wh or kWh
+func formatEnergy(joules float64) string {
+	wh := joules / 3600
+	if wh >= 1000 {
+		return fmt.Sprintf("%.2f kWh", wh/1000)
+	}
+	return fmt.Sprintf("%.2f Wh", wh)
+}
+
+// renderPowerPanel shows the draw of every RAPL domain and the GPUs with
+// the energy used since sysmon started
+func renderPowerPanel(m model) []string {
+	p := m.stats.Power
+	// Fixed columns: NAME (20) + WATTS (9) + ENERGY (12) + spacing (2) = 43
+	sparkWidth := m.width - 43 - 2
+
+	header := fmt.Sprintf("%-20s %9s %12s", "POWER", "DRAW", "SESSION")
+	if sparkWidth > 0 {
+		header += "  " + truncateLeft("HISTORY", sparkWidth)
+	}
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	lines := []string{headerStyle.Render(header)}
+
+	if len(p.Domains) == 0 && !p.HasGPU {
+		if p.Unreadable > 0 {
+			return append(lines, "(RAPL energy counters are not readable; run as root)")
+		}
+		return append(lines, "(no RAPL or GPU power readings)")
+	}
+
+	row := func(name string, watts, joules float64, key string) string {
+		line := fmt.Sprintf("%-20s %7.1f W %12s", truncateLeft(name, 20), watts, formatEnergy(joules))
+		if sparkWidth > 0 {
+			line += "  " + sparkline(m.history[key].last(sparkWidth), sparkWidth, 0)
+		}
+		return line
+	}
+
+	for _, d := range p.Domains {
+		lines = append(lines, row(d.Name, d.Watts, d.SessionJoules, "power:"+d.Zone))
+	}
+	if p.HasGPU {
+		lines = append(lines, row("gpu", p.GPUWatts, p.GPUSessionJoules, "power:gpu"))
+	}
+
+	boldStyle := lipgloss.NewStyle().Bold(true)
+	lines = append(lines, boldStyle.Render(row("total", p.TotalWatts(), p.TotalSessionJoules(), "power:total")))
+	lines = append(lines, fmt.Sprintf("session since %s (%s); total is packages + DRAM + GPU",
+		p.Since.Format("15:04:05"), time.Since(p.Since).Truncate(time.Second)))
+	if p.Unreadable > 0 {
+		lines = append(lines, fmt.Sprintf("%d zones not readable; run as root to see every domain", p.Unreadable))
+	}
+	return lines
+}
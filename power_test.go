@@ -0,0 +1,157 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadRAPL(t *testing.T) {
+	// psys has no readable energy_uj and the mmio zone duplicates package-0
+	readings, unreadable := readRAPL(filepath.Join("testdata", "sysfs", "powercap"))
+	want := []raplReading{
+		{Zone: "intel-rapl:0", Name: "package-0", EnergyUJ: 123456789, MaxEnergyUJ: 262143328850},
+		{Zone: "intel-rapl:0:0", Name: "core", EnergyUJ: 45678901, MaxEnergyUJ: 262143328850},
+		{Zone: "intel-rapl:0:1", Name: "uncore", EnergyUJ: 1234567, MaxEnergyUJ: 262143328850},
+		{Zone: "intel-rapl:0:2", Name: "dram", EnergyUJ: 9876543, MaxEnergyUJ: 262143328850},
+	}
+	if !reflect.DeepEqual(readings, want) {
+		t.Errorf("readRAPL =\n%+v\nexpected\n%+v", readings, want)
+	}
+	if unreadable != 1 {
+		t.Errorf("unreadable = %d; expected 1", unreadable)
+	}
+}
+
+func TestPowerCollectorSample(t *testing.T) {
+	c := &powerCollector{}
+	start := time.Unix(1000, 0)
+
+	first := []raplReading{
+		{Zone: "intel-rapl:0", Name: "package-0", EnergyUJ: 1000000, MaxEnergyUJ: 100000000},
+		{Zone: "intel-rapl:0:0", Name: "core", EnergyUJ: 500000, MaxEnergyUJ: 100000000},
+		{Zone: "intel-rapl:0:2", Name: "dram", EnergyUJ: 9000000, MaxEnergyUJ: 10000000},
+		{Zone: "intel-rapl:1", Name: "psys", EnergyUJ: 0, MaxEnergyUJ: 1000000000},
+	}
+	if got := c.sample(first, 100, true, start); len(got.Domains) != 0 || got.GPUSessionJoules != 0 {
+		t.Fatalf("first sample = %+v; expected only a baseline", got)
+	}
+
+	// Two seconds later: package +80 J, core +50 J, dram wraps for +4 J, psys +200 J
+	second := []raplReading{
+		{Zone: "intel-rapl:0", Name: "package-0", EnergyUJ: 81000000, MaxEnergyUJ: 100000000},
+		{Zone: "intel-rapl:0:0", Name: "core", EnergyUJ: 50500000, MaxEnergyUJ: 100000000},
+		{Zone: "intel-rapl:0:2", Name: "dram", EnergyUJ: 3000000, MaxEnergyUJ: 10000000},
+		{Zone: "intel-rapl:1", Name: "psys", EnergyUJ: 200000000, MaxEnergyUJ: 1000000000},
+	}
+	got := c.sample(second, 150, true, start.Add(2*time.Second))
+
+	want := []PowerDomain{
+		{Zone: "intel-rapl:0", Name: "package-0", Watts: 40, SessionJoules: 80},
+		{Zone: "intel-rapl:0:0", Name: "package-0/core", Watts: 25, SessionJoules: 50},
+		{Zone: "intel-rapl:0:2", Name: "package-0/dram", Watts: 2, SessionJoules: 4},
+		{Zone: "intel-rapl:1", Name: "psys", Watts: 100, SessionJoules: 200},
+	}
+	if !reflect.DeepEqual(got.Domains, want) {
+		t.Errorf("domains =\n%+v\nexpected\n%+v", got.Domains, want)
+	}
+
+	// Packages, DRAM and the GPU; core is inside the package and psys covers everything
+	if total := got.TotalWatts(); total != 40+2+150 {
+		t.Errorf("TotalWatts() = %.1f; expected 192", total)
+	}
+	if energy := got.TotalSessionJoules(); energy != 80+4+300 {
+		t.Errorf("TotalSessionJoules() = %.1f; expected 384", energy)
+	}
+	if !got.Since.Equal(start) {
+		t.Errorf("Since = %v; expected the first sample's time", got.Since)
+	}
+
+	// Session energy keeps accumulating
+	third := make([]raplReading, len(second))
+	copy(third, second)
+	third[0].EnergyUJ += 20000000
+	got = c.sample(third, 150, true, start.Add(3*time.Second))
+	if math.Abs(got.Domains[0].SessionJoules-100) > 1e-9 || got.GPUSessionJoules != 450 {
+		t.Errorf("session = %.1f J package, %.1f J GPU; expected 100 and 450", got.Domains[0].SessionJoules, got.GPUSessionJoules)
+	}
+}
+
+func TestPowerCollectorBackwardsCounter(t *testing.T) {
+	c := &powerCollector{}
+	start := time.Unix(1000, 0)
+
+	first := []raplReading{
+		{Zone: "intel-rapl:0", Name: "package-0", EnergyUJ: 9000000, MaxEnergyUJ: 0},
+		{Zone: "intel-rapl:1", Name: "package-1", EnergyUJ: 9000000, MaxEnergyUJ: 5000000},
+		{Zone: "intel-rapl:2", Name: "package-2", EnergyUJ: 1000000, MaxEnergyUJ: 0},
+	}
+	c.sample(first, 0, false, start)
+
+	// Both counters go backwards: one with no readable range, one reset
+	// below a range smaller than the previous value
+	second := []raplReading{
+		{Zone: "intel-rapl:0", Name: "package-0", EnergyUJ: 1000000, MaxEnergyUJ: 0},
+		{Zone: "intel-rapl:1", Name: "package-1", EnergyUJ: 1000000, MaxEnergyUJ: 5000000},
+		{Zone: "intel-rapl:2", Name: "package-2", EnergyUJ: 3000000, MaxEnergyUJ: 0},
+	}
+	got := c.sample(second, 0, false, start.Add(2*time.Second))
+	want := []PowerDomain{{Zone: "intel-rapl:2", Name: "package-2", Watts: 1, SessionJoules: 2}}
+	if !reflect.DeepEqual(got.Domains, want) {
+		t.Errorf("domains = %+v; expected only package-2", got.Domains)
+	}
+
+	// Counting resumes from the new values
+	second[0].EnergyUJ += 4000000
+	got = c.sample(second, 0, false, start.Add(4*time.Second))
+	if len(got.Domains) != 3 || got.Domains[0].SessionJoules != 4 {
+		t.Errorf("domains after the reset = %+v; expected package-0 to have used 4 J", got.Domains)
+	}
+}
+
+func TestFormatEnergy(t *testing.T) {
+	tests := map[float64]string{
+		0:        "0.00 Wh",
+		3600:     "1.00 Wh",
+		5400000:  "1.50 kWh",
+		36000000: "10.00 kWh",
+	}
+	for joules, want := range tests {
+		if got := formatEnergy(joules); got != want {
+			t.Errorf("formatEnergy(%v) = %q; expected %q", joules, got, want)
+		}
+	}
+}
+
+func TestRenderPowerPanel(t *testing.T) {
+	m := model{width: 40}
+	if lines := renderPowerPanel(m); !strings.Contains(lines[1], "no RAPL") {
+		t.Errorf("empty panel = %q", lines)
+	}
+	m.stats.Power.Unreadable = 2
+	if lines := renderPowerPanel(m); !strings.Contains(lines[1], "run as root") {
+		t.Errorf("unreadable panel = %q", lines)
+	}
+
+	m.stats.Power = PowerStats{
+		Domains:          []PowerDomain{{Zone: "intel-rapl:0", Name: "package-0", Watts: 42.5, SessionJoules: 7200}},
+		GPUWatts:         250,
+		HasGPU:           true,
+		GPUSessionJoules: 36000,
+		Since:            time.Now(),
+	}
+	lines := renderPowerPanel(m)
+	want := []string{
+		"package-0               42.5 W      2.00 Wh",
+		"gpu                    250.0 W     10.00 Wh",
+		"total                  292.5 W     12.00 Wh",
+	}
+	for i, w := range want {
+		if got := stripAnsiCodes(lines[i+1]); got != w {
+			t.Errorf("line %d = %q; expected %q", i+1, got, w)
+		}
+	}
+}
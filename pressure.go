@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PressureLine is one "some" or "full" line of a PSI file. The averages are
+// the percentage of wall time tasks were stalled over 10s, 60s and 300s.
+type PressureLine struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	// Total is the cumulative stall time in microseconds
+	Total uint64
+}
+
+// Pressure is the PSI data for one resource, system-wide or for one cgroup
+type Pressure struct {
+	Resource string
+	// Cgroup is the cgroup path relative to the cgroup root, "" system-wide
+	Cgroup  string
+	Some    PressureLine
+	Full    PressureLine
+	HasFull bool
+}
+
+// Resources with PSI accounting
+var pressureResources = []string{"cpu", "memory", "io"}
+
+// pressureCgroups lists the cgroups whose *.pressure files are shown next to
+// the system-wide values, set from the config at startup
+var pressureCgroups []string
+
+// cgroupRoot is where the cgroup v2 hierarchy is mounted
+func cgroupRoot() string {
+	return filepath.Join(sysfsRoot, "fs", "cgroup")
+}
+
+// getPressure reads <procRoot>/pressure/* and the configured cgroups'
+// *.pressure files. Kernels without PSI (or with psi=0) yield nothing.
+func getPressure(procRoot, cgRoot string, cgroups []string) []Pressure {
+	var result []Pressure
+	for _, resource := range pressureResources {
+		if p, err := readPressureFile(filepath.Join(procRoot, "pressure", resource)); err == nil {
+			p.Resource = resource
+			result = append(result, p)
+		}
+	}
+
+	for _, cg := range cgroups {
+		for _, resource := range pressureResources {
+			p, err := readPressureFile(filepath.Join(cgRoot, cg, resource+".pressure"))
+			if err != nil {
+				continue
+			}
+			p.Resource = resource
+			p.Cgroup = cg
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// readPressureFile parses a PSI file:
+//
+//	some avg10=0.12 avg60=0.05 avg300=0.01 total=123456
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=7890
+func readPressureFile(path string) (Pressure, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Pressure{}, err
+	}
+	defer f.Close()
+
+	var p Pressure
+	found := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		var line PressureLine
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "avg10":
+				line.Avg10, _ = strconv.ParseFloat(value, 64)
+			case "avg60":
+				line.Avg60, _ = strconv.ParseFloat(value, 64)
+			case "avg300":
+				line.Avg300, _ = strconv.ParseFloat(value, 64)
+			case "total":
+				line.Total, _ = strconv.ParseUint(value, 10, 64)
+			}
+		}
+
+		switch fields[0] {
+		case "some":
+			p.Some = line
+			found = true
+		case "full":
+			p.Full = line
+			p.HasFull = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Pressure{}, err
+	}
+	if !found {
+		return Pressure{}, fmt.Errorf("%s: no \"some\" line", path)
+	}
+	return p, nil
+}
+
+// pressureKey names the history series of a resource's some/full avg10
+func pressureKey(p Pressure, kind string) string {
+	return "psi:" + p.Cgroup + ":" + p.Resource + ":" + kind
+}
+
+// recordPressureHistory appends the avg10 samples used by the sparklines
+func recordPressureHistory(h history, pressures []Pressure) {
+	for _, p := range pressures {
+		h.record(pressureKey(p, "some"), p.Some.Avg10)
+		if p.HasFull {
+			h.record(pressureKey(p, "full"), p.Full.Avg10)
+		}
+	}
+}
+
+// renderPressurePanel renders a bar of avg10 per resource and kind with the
+// longer averages and an avg10 sparkline
+func renderPressurePanel(m model) []string {
+	// Fixed columns: NAME (24) + KIND (4) + bar (20) + AVG60/AVG300 (2x7) + spacing (4) = 66
+	sparkWidth := m.width - 66 - 2
+
+	header := fmt.Sprintf("%-24s %-4s %-20s %7s %7s", "PRESSURE", "", "AVG10", "AVG60", "AVG300")
+	if sparkWidth > 0 {
+		header += "  " + truncateLeft("HISTORY", sparkWidth)
+	}
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	lines := []string{headerStyle.Render(header)}
+
+	if len(m.stats.Pressure) == 0 {
+		return append(lines, "(PSI not available; needs Linux 4.20+ with CONFIG_PSI)")
+	}
+
+	for _, p := range m.stats.Pressure {
+		name := p.Resource
+		if p.Cgroup != "" {
+			name = p.Cgroup + " " + p.Resource
+		}
+
+		kinds := []string{"some"}
+		if p.HasFull {
+			kinds = append(kinds, "full")
+		}
+
+		for i, kind := range kinds {
+			stall := p.Some
+			if kind == "full" {
+				stall = p.Full
+			}
+
+			label := ""
+			if i == 0 {
+				label = truncateLeft(name, 24)
+			}
+			bar := createBarWithText("", fmt.Sprintf("%.2f%%", stall.Avg10), stall.Avg10, 20, getColorStyle(stall.Avg10))
+			line := fmt.Sprintf("%-24s %-4s %s %6.2f%% %6.2f%%", label, kind, bar, stall.Avg60, stall.Avg300)
+			if sparkWidth > 0 {
+				values := m.history[pressureKey(p, kind)].last(sparkWidth)
+				line += "  " + sparkline(values, sparkWidth, pressureSparkScale(values))
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// pressureSparkScale scales sparklines to their peak but never below 10%, so
+// background noise of a few hundredths of a percent doesn't look like a spike
+func pressureSparkScale(values []float64) float64 {
+	scale := 10.0
+	for _, v := range values {
+		if v > scale {
+			scale = v
+		}
+	}
+	return scale
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetPressure(t *testing.T) {
+	pressures := getPressure(
+		filepath.Join("testdata", "proc"),
+		filepath.Join("testdata", "sysfs", "psi", "fs", "cgroup"),
+		[]string{"system.slice", "missing.slice"},
+	)
+
+	// 3 system-wide resources, plus cpu and io for system.slice
+	if len(pressures) != 5 {
+		t.Fatalf("getPressure returned %d entries; expected 5: %+v", len(pressures), pressures)
+	}
+
+	io := pressures[2]
+	if io.Resource != "io" || io.Cgroup != "" {
+		t.Fatalf("pressures[2] = %s/%q; expected system-wide io", io.Resource, io.Cgroup)
+	}
+	if io.Some.Avg10 != 45.1 || io.Some.Avg60 != 30 || io.Some.Avg300 != 10 || io.Some.Total != 987654321 {
+		t.Errorf("io some = %+v", io.Some)
+	}
+	if !io.HasFull || io.Full.Avg10 != 40 || io.Full.Avg60 != 25.5 {
+		t.Errorf("io full = %+v (has=%v)", io.Full, io.HasFull)
+	}
+
+	cgCPU := pressures[3]
+	if cgCPU.Cgroup != "system.slice" || cgCPU.Resource != "cpu" || cgCPU.HasFull || cgCPU.Some.Avg10 != 3 {
+		t.Errorf("system.slice cpu = %+v; expected some-only avg10=3", cgCPU)
+	}
+}
+
+func TestReadPressureFileRejectsGarbage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cpu")
+	if _, err := readPressureFile(path); err == nil {
+		t.Error("readPressureFile on a missing file succeeded")
+	}
+
+	if err := os.WriteFile(path, []byte("not a pressure file\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readPressureFile(path); err == nil {
+		t.Error("readPressureFile accepted a file without a \"some\" line")
+	}
+}
+
+func TestPressurePanelRenders(t *testing.T) {
+	m := model{
+		width:   120,
+		height:  40,
+		panels:  map[string]bool{"pressure": true},
+		history: history{},
+		stats: SystemStats{
+			Pressure: getPressure(filepath.Join("testdata", "proc"), filepath.Join("testdata", "sysfs", "psi", "fs", "cgroup"), []string{"system.slice"}),
+		},
+	}
+	recordPressureHistory(m.history, m.stats.Pressure)
+
+	view := stripAnsiCodes(m.View())
+	for _, want := range []string{"PRESSURE", "io", "45.10%", "25.50%", "system.slice io", "90.00%"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected pressure panel to contain %q:\n%s", want, view)
+		}
+	}
+
+	m.stats.Pressure = nil
+	if view := m.View(); !strings.Contains(view, "PSI not available") {
+		t.Error("expected a notice when PSI is unavailable")
+	}
+}
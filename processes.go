@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// processSort is the column the process list is sorted by
+type processSort int
+
+const (
+	processSortCPU processSort = iota
+	processSortMemory
+	processSortRead
+	processSortWrite
+	processSortCount
+)
+
+// sortProcesses returns procs sorted by column, largest first, or smallest
+// first when reverse is set. Ties keep the collection order.
+func sortProcesses(procs []ProcessInfo, column processSort, reverse bool) []ProcessInfo {
+	key := func(p ProcessInfo) float64 {
+		switch column {
+		case processSortMemory:
+			// The percentage is RSS over total memory, so this also orders
+			// by RSS in bytes mode
+			return float64(p.Memory)
+		case processSortRead:
+			return p.ReadBytesPerS
+		case processSortWrite:
+			return p.WriteBytesPerS
+		}
+		return p.CPU
+	}
+
+	sorted := append([]ProcessInfo(nil), procs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if reverse {
+			return key(sorted[i]) < key(sorted[j])
+		}
+		return key(sorted[i]) > key(sorted[j])
+	})
+	return sorted
+}
+
+// handleProcessSortKey changes the sort column of the process list or
+// switches its memory between percentages and bytes, reporting whether the
+// key was consumed
+func (m model) handleProcessSortKey(key string) (model, bool) {
+	switch key {
+	case ">", ".":
+		m.processSort = (m.processSort + 1) % processSortCount
+	case "<", ",":
+		m.processSort = (m.processSort + processSortCount - 1) % processSortCount
+	case "r":
+		m.processReverse = !m.processReverse
+	case "b":
+		m.processBytes = !m.processBytes
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+// processColumnLabels returns the headers of the sortable process columns,
+// indexed by processSort, with the sort column marked
+func (m model) processColumnLabels() []string {
+	labels := []string{"CPU%", "MEM%", "READ/s", "WRITE/s"}
+	if m.processReverse {
+		labels[m.processSort] += "▲"
+	} else {
+		labels[m.processSort] += "▼"
+	}
+	return labels
+}
+
+// Width of each memory column in bytes mode, enough for "1023.9M"
+const processBytesWidth = 7
+
+// memoryColumnsWidth is the width of the memory columns: MEM% (5), or in
+// bytes mode RSS, VMS and SHR, followed by PSS, USS and SWAP with
+// smaps_rollup
+func (m model) memoryColumnsWidth() int {
+	if !m.processBytes {
+		return 5
+	}
+	n := 3
+	if readSmaps {
+		n = 6
+	}
+	return n*processBytesWidth + (n-1)*2
+}
+
+// processColumns are the optional columns of the process list
+type processColumns struct {
+	io        bool
+	container bool
+}
+
+// processListColumns picks the optional columns for the listed processes:
+// CONTAINER only when one of them belongs to a workload, and READ/s and
+// WRITE/s unless they would squeeze COMMAND below its minimum width, which
+// they're kept for while the list is sorted by them
+func (m model) processListColumns(procs []ProcessInfo) processColumns {
+	var cols processColumns
+	for _, p := range procs {
+		if p.Workload != (Workload{}) {
+			cols.container = true
+			break
+		}
+	}
+	cols.io = true
+	if m.processSort != processSortRead && m.processSort != processSortWrite {
+		cols.io = m.width-m.processFixedWidth(cols) >= minCommandWidth
+	}
+	return cols
+}
+
+// processFixedWidth is the width of every process list column but COMMAND
+func (m model) processFixedWidth(cols processColumns) int {
+	width := fixedColumnsWidth - 5 + m.memoryColumnsWidth()
+	if cols.io {
+		width += ioColumnsWidth
+	}
+	if cols.container {
+		width += containerColumnWidth + 2
+	}
+	return width
+}
+
+// processMemoryHeader renders the headers of the memory columns, with label
+// (MEM% and its sort marker) on the percentage or RSS column
+func (m model) processMemoryHeader(label string) string {
+	if !m.processBytes {
+		return fmt.Sprintf("%5s", label)
+	}
+	header := fmt.Sprintf("%7s  %7s  %7s", strings.Replace(label, "MEM%", "RSS", 1), "VMS", "SHR")
+	if readSmaps {
+		header += fmt.Sprintf("  %7s  %7s  %7s", "PSS", "USS", "SWAP")
+	}
+	return header
+}
+
+// processMemoryCells renders a process's memory columns, colored by its
+// share of system memory
+func (m model) processMemoryCells(p ProcessInfo) string {
+	style := getColorStyle(float64(p.Memory)).Underline(false)
+	if !m.processBytes {
+		return style.Render(fmt.Sprintf("%5.1f", p.Memory))
+	}
+
+	rss := style.Render(fmt.Sprintf("%7s", formatBytes(float64(p.RSS))))
+	cells := fmt.Sprintf("%s  %7s  %7s", rss, formatBytes(float64(p.VMS)), formatBytes(float64(p.Shared)))
+	if !readSmaps {
+		return cells
+	}
+	pss, uss, swap := "-", "-", "-"
+	if p.HasSmaps {
+		pss, uss, swap = formatBytes(float64(p.PSS)), formatBytes(float64(p.USS)), formatBytes(float64(p.Swap))
+	}
+	return cells + fmt.Sprintf("  %7s  %7s  %7s", pss, uss, swap)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestSortProcesses(t *testing.T) {
+	procs := []ProcessInfo{
+		{PID: 1, CPU: 50, Memory: 1, ReadBytesPerS: 10},
+		{PID: 2, CPU: 10, Memory: 9, WriteBytesPerS: 4096},
+		{PID: 3, CPU: 30, Memory: 5, ReadBytesPerS: 8192},
+	}
+	pids := func(procs []ProcessInfo) []int32 {
+		var pids []int32
+		for _, p := range procs {
+			pids = append(pids, p.PID)
+		}
+		return pids
+	}
+
+	tests := []struct {
+		column  processSort
+		reverse bool
+		want    []int32
+	}{
+		{processSortCPU, false, []int32{1, 3, 2}},
+		{processSortMemory, false, []int32{2, 3, 1}},
+		{processSortRead, false, []int32{3, 1, 2}},
+		{processSortWrite, false, []int32{2, 1, 3}},
+		{processSortCPU, true, []int32{2, 3, 1}},
+	}
+	for _, tt := range tests {
+		got := pids(sortProcesses(procs, tt.column, tt.reverse))
+		if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] || got[2] != tt.want[2] {
+			t.Errorf("sortProcesses(%d, %v) = %v; expected %v", tt.column, tt.reverse, got, tt.want)
+		}
+	}
+	if procs[0].PID != 1 {
+		t.Error("sortProcesses modified its argument")
+	}
+}
+
+func TestViewProcessIO(t *testing.T) {
+	m := model{
+		width:  120,
+		height: 24,
+		stats: SystemStats{
+			CPUCores: []float64{10},
+			Processes: []ProcessInfo{
+				{PID: 100, CPU: 40, Command: "/usr/bin/busy", HasIO: true},
+				{PID: 200, CPU: 1, Command: "/usr/bin/writer", HasIO: true, WriteBytesPerS: 50 << 20},
+				{PID: 300, CPU: 0, Command: "/usr/sbin/other"},
+			},
+			ProcessIOUnreadable: 1,
+		},
+	}
+
+	view := stripAnsiCodes(m.View())
+	if !strings.Contains(view, "CPU%▼") || !strings.Contains(view, "50.0M/s") {
+		t.Errorf("process list is missing the I/O columns:\n%s", view)
+	}
+	if strings.Contains(view, "CONTAINER") {
+		t.Errorf("CONTAINER column shown without any process in a workload:\n%s", view)
+	}
+	if strings.Contains(view, "is not readable") {
+		t.Errorf("the note about unreadable I/O should only show when sorting by I/O:\n%s", view)
+	}
+
+	// Sort by write rate: CPU% -> MEM% -> READ/s -> WRITE/s
+	for i := 0; i < 3; i++ {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">")})
+		m = next.(model)
+	}
+	view = stripAnsiCodes(m.View())
+	if !strings.Contains(view, "WRITE/s▼") || strings.Index(view, "/usr/bin/writer") > strings.Index(view, "/usr/bin/busy") {
+		t.Errorf("sorting by WRITE/s should put the writer first:\n%s", view)
+	}
+	if !strings.Contains(view, "I/O of 1 processes is not readable") {
+		t.Errorf("process list sorted by I/O has no note about unreadable I/O:\n%s", view)
+	}
+}
+
+func TestViewProcessNarrow(t *testing.T) {
+	m := model{
+		width:  80,
+		height: 24,
+		stats: SystemStats{
+			CPUCores: []float64{10},
+			Processes: []ProcessInfo{
+				{PID: 100, CPU: 40, Command: "/usr/bin/busy", HasIO: true, ReadBytesPerS: 1 << 20,
+					Workload: Workload{Runtime: "docker", ContainerID: testContainerID}},
+			},
+		},
+	}
+
+	view := stripAnsiCodes(m.View())
+	if strings.Contains(view, "READ/s") || !strings.Contains(view, "CONTAINER") {
+		t.Errorf("an 80 column terminal should drop the I/O columns for CONTAINER:\n%s", view)
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if w := lipgloss.Width(line); w > m.width {
+			t.Errorf("line is %d columns wide on an %d column terminal: %q", w, m.width, line)
+		}
+	}
+
+	// Sort by read rate: CPU% -> MEM% -> READ/s
+	for i := 0; i < 2; i++ {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">")})
+		m = next.(model)
+	}
+	if view := stripAnsiCodes(m.View()); !strings.Contains(view, "READ/s▼") || !strings.Contains(view, "1.0M/s") {
+		t.Errorf("sorting by READ/s should show the I/O columns:\n%s", view)
+	}
+}
+
+func TestViewProcessMemoryBytes(t *testing.T) {
+	m := model{
+		width:  140,
+		height: 24,
+		stats: SystemStats{
+			CPUCores: []float64{10},
+			Processes: []ProcessInfo{
+				{PID: 100, CPU: 5, Memory: 9.4, Command: "/usr/bin/worker", RSS: 3 << 30, VMS: 8 << 30, Shared: 2800 << 20,
+					PSS: 600 << 20, USS: 200 << 20, Swap: 0, HasSmaps: true},
+				{PID: 200, CPU: 1, Memory: 1, Command: "/usr/bin/other", RSS: 100 << 20},
+			},
+		},
+	}
+	press := func() {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+		m = next.(model)
+	}
+
+	press()
+	view := stripAnsiCodes(m.View())
+	for _, want := range []string{"RSS", "VMS", "SHR", "3.0G", "8.0G", "2.7G"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("bytes mode is missing %q:\n%s", want, view)
+		}
+	}
+
+	defer func(old bool) { readSmaps = old }(readSmaps)
+	readSmaps = true
+	view = stripAnsiCodes(m.View())
+	for _, want := range []string{"VMS", "SHR", "8.0G", "PSS", "USS", "SWAP", "600.0M", "200.0M"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("bytes mode with smaps is missing %q:\n%s", want, view)
+		}
+	}
+	if !strings.Contains(view, "100.0M       0B       0B        -        -        -") {
+		t.Errorf("a process without smaps_rollup should show dashes:\n%s", view)
+	}
+
+	press()
+	if view := stripAnsiCodes(m.View()); !strings.Contains(view, "MEM%") || strings.Contains(view, "PSS") {
+		t.Errorf("b should switch back to percentages:\n%s", view)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// procIOCounters are a process's cumulative storage I/O from /proc/<pid>/io,
+// with its start time to tell a reused PID from the process before it
+type procIOCounters struct {
+	created    int64
+	readBytes  uint64
+	writeBytes uint64
+}
+
+// processIORate is a process's read and write throughput in bytes/s
+type processIORate struct {
+	read  float64
+	write float64
+}
+
+// processIOCollector turns per-process I/O counters into rates
+type processIOCollector struct {
+	mu       sync.Mutex
+	prev     map[int32]procIOCounters
+	prevTime time.Time
+}
+
+var processIOStats = &processIOCollector{}
+
+// sample returns the rates of the processes in cur since the previous call.
+// Processes that weren't seen then, including new processes reusing a PID,
+// are missing from the result until their second sample.
+func (c *processIOCollector) sample(cur map[int32]procIOCounters, now time.Time) map[int32]processIORate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev, prevTime := c.prev, c.prevTime
+	c.prev, c.prevTime = cur, now
+
+	rates := make(map[int32]processIORate)
+	elapsed := now.Sub(prevTime).Seconds()
+	if prevTime.IsZero() || elapsed <= 0 {
+		return rates
+	}
+	for pid, counters := range cur {
+		p, ok := prev[pid]
+		if !ok || p.created != counters.created {
+			continue
+		}
+		rates[pid] = processIORate{
+			read:  counterDelta(counters.readBytes, p.readBytes) / elapsed,
+			write: counterDelta(counters.writeBytes, p.writeBytes) / elapsed,
+		}
+	}
+	return rates
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProcessIOCollectorSample(t *testing.T) {
+	c := &processIOCollector{}
+	start := time.Unix(1000, 0)
+
+	if rates := c.sample(map[int32]procIOCounters{10: {created: 1, readBytes: 1000}}, start); len(rates) != 0 {
+		t.Errorf("first sample = %v; expected only a baseline", rates)
+	}
+
+	rates := c.sample(map[int32]procIOCounters{
+		10: {created: 1, readBytes: 7000, writeBytes: 3000},
+		// A new process
+		11: {created: 5, readBytes: 500},
+	}, start.Add(3*time.Second))
+	if got := rates[10]; got.read != 2000 || got.write != 1000 {
+		t.Errorf("pid 10 = %+v; expected 2000 B/s read and 1000 written", got)
+	}
+	if _, ok := rates[11]; ok {
+		t.Error("a process seen for the first time should have no rate")
+	}
+
+	// PID 10 exited and was reused with lower counters
+	rates = c.sample(map[int32]procIOCounters{10: {created: 9, readBytes: 100}}, start.Add(6*time.Second))
+	if _, ok := rates[10]; ok {
+		t.Errorf("a reused PID got rate %+v; expected none", rates[10])
+	}
+}
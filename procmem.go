@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readSmaps enables reading /proc/<pid>/smaps_rollup for PSS, USS and swap,
+// set from the config at startup. The kernel walks the page tables of the
+// whole process to produce it, so it is off by default.
+var readSmaps bool
+
+// smapsRollup is the memory of a process from /proc/<pid>/smaps_rollup, in
+// bytes
+type smapsRollup struct {
+	// PSS divides shared pages among the processes sharing them
+	PSS uint64
+	// USS is the memory only this process maps, freed when it exits
+	USS  uint64
+	Swap uint64
+}
+
+// readSmapsRollup reads <root>/<pid>/smaps_rollup, which is only readable
+// for processes we may ptrace
+func readSmapsRollup(root string, pid int32) (smapsRollup, error) {
+	data, err := os.ReadFile(filepath.Join(root, strconv.Itoa(int(pid)), "smaps_rollup"))
+	if err != nil {
+		return smapsRollup{}, err
+	}
+	return parseSmapsRollup(data), nil
+}
+
+// parseSmapsRollup parses the "Key:    1234 kB" lines of smaps_rollup
+func parseSmapsRollup(data []byte) smapsRollup {
+	var s smapsRollup
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "Pss:":
+			s.PSS = kb * 1024
+		case "Private_Clean:", "Private_Dirty:":
+			s.USS += kb * 1024
+		case "Swap:":
+			s.Swap = kb * 1024
+		}
+	}
+	return s
+}
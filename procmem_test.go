@@ -0,0 +1,23 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSmapsRollup(t *testing.T) {
+	got, err := readSmapsRollup(filepath.Join("testdata", "proc"), 100)
+	if err != nil {
+		t.Fatalf("readSmapsRollup error = %v", err)
+	}
+	// USS is Private_Clean + Private_Dirty
+	want := smapsRollup{PSS: 6144 << 10, USS: 4096 << 10, Swap: 512 << 10}
+	if got != want {
+		t.Errorf("readSmapsRollup = %+v; expected %+v", got, want)
+	}
+
+	// Other users' processes can't be read without root
+	if _, err := readSmapsRollup(filepath.Join("testdata", "proc"), 200); err == nil {
+		t.Error("readSmapsRollup without a smaps_rollup file succeeded")
+	}
+}
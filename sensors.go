@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Sensor is one hwmon or thermal zone reading
+type Sensor struct {
+	// Chip is the hwmon driver name (coretemp, nvme, nct6775, ...) or the
+	// thermal zone type, with the device appended when a name repeats
+	Chip  string
+	Label string
+	// Kind is "temp" (Value in °C) or "fan" (Value in RPM)
+	Kind  string
+	Value float64
+	// Max and Crit are the warning and critical temperatures, Min the fan
+	// speed alarm threshold; zero when the driver doesn't report one
+	Max  float64
+	Crit float64
+	Min  float64
+}
+
+// style colors a reading by its thresholds. Temperatures without a warning
+// threshold turn yellow 10°C below critical.
+func (s Sensor) style() lipgloss.Style {
+	if s.Kind == "fan" {
+		if s.Min > 0 && s.Value < s.Min {
+			return redStyle
+		}
+		return greenStyle
+	}
+
+	switch {
+	case s.Crit > 0 && s.Value >= s.Crit:
+		return redStyle
+	case s.Max > 0 && s.Value >= s.Max:
+		return yellowStyle
+	case s.Max == 0 && s.Crit > 0 && s.Value >= s.Crit-10:
+		return yellowStyle
+	}
+	return greenStyle
+}
+
+// text formats the reading, e.g. "62°C" or "1200rpm"
+func (s Sensor) text() string {
+	if s.Kind == "fan" {
+		return fmt.Sprintf("%.0frpm", s.Value)
+	}
+	return fmt.Sprintf("%.0f°C", s.Value)
+}
+
+// getSensors reads every hwmon chip under <root>/class/hwmon, then the
+// thermal zones under <root>/class/thermal that no hwmon chip already covers
+func getSensors(root string) []Sensor {
+	dirs, _ := filepath.Glob(filepath.Join(root, "class", "hwmon", "hwmon[0-9]*"))
+	sort.Slice(dirs, func(i, j int) bool { return sysfsIndex(dirs[i], "hwmon") < sysfsIndex(dirs[j], "hwmon") })
+
+	// Disambiguate chips sharing a driver name, e.g. several NVMe drives
+	names := make([]string, len(dirs))
+	count := make(map[string]int)
+	for i, dir := range dirs {
+		names[i] = readSysfsString(filepath.Join(dir, "name"))
+		count[names[i]]++
+	}
+
+	var result []Sensor
+	covered := make(map[string]bool)
+	for i, dir := range dirs {
+		if names[i] == "" {
+			continue
+		}
+		covered[names[i]] = true
+
+		chip := names[i]
+		if count[chip] > 1 {
+			if target, err := os.Readlink(filepath.Join(dir, "device")); err == nil {
+				chip += " " + filepath.Base(target)
+			} else {
+				chip += " " + filepath.Base(dir)
+			}
+		}
+		result = append(result, readHwmon(dir, chip)...)
+	}
+
+	return append(result, readThermalZones(root, covered)...)
+}
+
+// readHwmon reads the temp*_input and fan*_input attributes of one chip in
+// channel order
+func readHwmon(dir, chip string) []Sensor {
+	var result []Sensor
+	for _, kind := range []string{"temp", "fan"} {
+		inputs, _ := filepath.Glob(filepath.Join(dir, kind+"[0-9]*_input"))
+		sort.Slice(inputs, func(i, j int) bool { return sysfsIndex(inputs[i], kind) < sysfsIndex(inputs[j], kind) })
+
+		for _, input := range inputs {
+			prefix := strings.TrimSuffix(input, "_input")
+			value, err := readSysfsFloat(input)
+			if err != nil {
+				continue
+			}
+			label := readSysfsString(prefix + "_label")
+			if label == "" {
+				label = filepath.Base(prefix)
+			}
+
+			s := Sensor{Chip: chip, Label: label, Kind: kind, Value: value}
+			if kind == "temp" {
+				// hwmon temperatures are in millidegrees
+				s.Value /= 1000
+				s.Max = readMillidegrees(prefix + "_max")
+				s.Crit = readMillidegrees(prefix + "_crit")
+			} else {
+				s.Min, _ = readSysfsFloat(prefix + "_min")
+			}
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// readThermalZones reads thermal_zone* whose type isn't in skip, taking the
+// thresholds from the "hot" (or "passive") and "critical" trip points
+func readThermalZones(root string, skip map[string]bool) []Sensor {
+	zones, _ := filepath.Glob(filepath.Join(root, "class", "thermal", "thermal_zone[0-9]*"))
+	sort.Slice(zones, func(i, j int) bool {
+		return sysfsIndex(zones[i], "thermal_zone") < sysfsIndex(zones[j], "thermal_zone")
+	})
+
+	var result []Sensor
+	for _, zone := range zones {
+		zoneType := readSysfsString(filepath.Join(zone, "type"))
+		if zoneType == "" || skip[zoneType] {
+			continue
+		}
+		temp, err := readSysfsFloat(filepath.Join(zone, "temp"))
+		if err != nil {
+			continue
+		}
+
+		s := Sensor{Chip: zoneType, Label: filepath.Base(zone), Kind: "temp", Value: temp / 1000}
+		trips, _ := filepath.Glob(filepath.Join(zone, "trip_point_[0-9]*_type"))
+		for _, trip := range trips {
+			tripTemp := readMillidegrees(strings.TrimSuffix(trip, "_type") + "_temp")
+			switch readSysfsString(trip) {
+			case "critical":
+				s.Crit = tripTemp
+			case "hot":
+				s.Max = tripTemp
+			case "passive":
+				if s.Max == 0 {
+					s.Max = tripTemp
+				}
+			}
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// readMillidegrees reads a millidegree attribute as °C, 0 when missing
+func readMillidegrees(path string) float64 {
+	v, err := readSysfsFloat(path)
+	if err != nil || v <= 0 {
+		return 0
+	}
+	return v / 1000
+}
+
+// sysfsIndex extracts N from names such as hwmonN or tempN_input so that
+// hwmon10 sorts after hwmon9
+func sysfsIndex(path, prefix string) int {
+	name := strings.TrimPrefix(filepath.Base(path), prefix)
+	end := 0
+	for end < len(name) && name[end] >= '0' && name[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(name[:end])
+	return n
+}
+
+// sensorThresholds formats a temperature's thresholds, e.g. "max 80°C crit 100°C"
+func sensorThresholds(s Sensor) string {
+	var parts []string
+	if s.Max > 0 {
+		parts = append(parts, fmt.Sprintf("max %.0f°C", s.Max))
+	}
+	if s.Crit > 0 {
+		parts = append(parts, fmt.Sprintf("crit %.0f°C", s.Crit))
+	}
+	return strings.Join(parts, " ")
+}
+
+// renderSensorsPanel lists each chip's readings on one line, wrapping to the
+// terminal width, colored by their thresholds. Thresholds shared by all of a
+// chip's temperatures are shown once after its readings, others next to
+// each reading.
+func renderSensorsPanel(m model) []string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	boldStyle := lipgloss.NewStyle().Bold(true)
+	faintStyle := lipgloss.NewStyle().Faint(true)
+	lines := []string{headerStyle.Render("SENSORS")}
+
+	if len(m.stats.Sensors) == 0 {
+		return append(lines, "(no hwmon or thermal sensors found)")
+	}
+
+	const chipWidth = 16
+	indent := strings.Repeat(" ", chipWidth+1)
+	sensors := m.stats.Sensors
+	for start := 0; start < len(sensors); {
+		end := start
+		for end < len(sensors) && sensors[end].Chip == sensors[start].Chip {
+			end++
+		}
+		chip := sensors[start:end]
+		start = end
+
+		// Thresholds common to every temperature of the chip
+		shared, common, seen := "", true, false
+		for _, s := range chip {
+			if s.Kind != "temp" {
+				continue
+			}
+			if t := sensorThresholds(s); !seen {
+				shared, seen = t, true
+			} else if t != shared {
+				common = false
+			}
+		}
+
+		line := boldStyle.Render(fmt.Sprintf("%-*s", chipWidth, truncateLeft(chip[0].Chip, chipWidth))) + " "
+		used := chipWidth + 1
+		add := func(plain, styled string) {
+			width := len([]rune(plain)) + 2
+			if used > chipWidth+1 && used+width > m.width {
+				lines = append(lines, strings.TrimRight(line, " "))
+				line, used = indent, chipWidth+1
+			}
+			line += styled + "  "
+			used += width
+		}
+
+		for _, s := range chip {
+			plain := s.Label + " " + s.text()
+			styled := s.Label + " " + s.style().Render(s.text())
+			if t := sensorThresholds(s); s.Kind == "temp" && !common && t != "" {
+				plain += " (" + t + ")"
+				styled += faintStyle.Render(" (" + t + ")")
+			}
+			add(plain, styled)
+		}
+		if common && shared != "" {
+			add(shared, faintStyle.Render(shared))
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return lines
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetSensors(t *testing.T) {
+	got := getSensors(filepath.Join("testdata", "sysfs", "sensors"))
+	want := []Sensor{
+		{Chip: "coretemp", Label: "Package id 0", Kind: "temp", Value: 62, Max: 80, Crit: 100},
+		{Chip: "coretemp", Label: "Core 0", Kind: "temp", Value: 85, Max: 80, Crit: 100},
+		{Chip: "coretemp", Label: "Core 8", Kind: "temp", Value: 101, Max: 80, Crit: 100},
+		{Chip: "nvme nvme0", Label: "Composite", Kind: "temp", Value: 45.85, Max: 84.85, Crit: 89.85},
+		{Chip: "nvme nvme1", Label: "Composite", Kind: "temp", Value: 39.85},
+		{Chip: "nct6775", Label: "SYSTIN", Kind: "temp", Value: 35},
+		{Chip: "nct6775", Label: "CPU Fan", Kind: "fan", Value: 1200},
+		{Chip: "nct6775", Label: "fan2", Kind: "fan", Value: 0, Min: 300},
+		{Chip: "acpitz", Label: "temp1", Kind: "temp", Value: 27.8, Crit: 105},
+		// thermal_zone0 is acpitz, already read through hwmon
+		{Chip: "x86_pkg_temp", Label: "thermal_zone1", Kind: "temp", Value: 63, Max: 90, Crit: 100},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getSensors =\n%+v\nexpected\n%+v", got, want)
+	}
+}
+
+func TestSensorStyle(t *testing.T) {
+	tests := []struct {
+		name   string
+		sensor Sensor
+		want   string
+	}{
+		{"below max", Sensor{Kind: "temp", Value: 62, Max: 80, Crit: 100}, "green"},
+		{"above max", Sensor{Kind: "temp", Value: 85, Max: 80, Crit: 100}, "yellow"},
+		{"above crit", Sensor{Kind: "temp", Value: 101, Max: 80, Crit: 100}, "red"},
+		{"near crit without max", Sensor{Kind: "temp", Value: 96, Crit: 105}, "yellow"},
+		{"no thresholds", Sensor{Kind: "temp", Value: 120}, "green"},
+		{"fan stopped below min", Sensor{Kind: "fan", Value: 0, Min: 300}, "red"},
+		{"fan without min", Sensor{Kind: "fan", Value: 0}, "green"},
+	}
+
+	styles := map[string]string{
+		"green":  greenStyle.Render("x"),
+		"yellow": yellowStyle.Render("x"),
+		"red":    redStyle.Render("x"),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sensor.style().Render("x"); got != styles[tt.want] {
+				t.Errorf("style() renders %q; expected %s (%q)", got, tt.want, styles[tt.want])
+			}
+		})
+	}
+}
+
+func TestRenderSensorsPanel(t *testing.T) {
+	m := model{width: 80, stats: SystemStats{Sensors: getSensors(filepath.Join("testdata", "sysfs", "sensors"))}}
+	lines := renderSensorsPanel(m)
+
+	var text []string
+	for _, line := range lines {
+		text = append(text, stripAnsiCodes(line))
+	}
+	want := []string{
+		"SENSORS",
+		"coretemp         Package id 0 62°C  Core 0 85°C  Core 8 101°C",
+		"                 max 80°C crit 100°C",
+		"nvme nvme0       Composite 46°C  max 85°C crit 90°C",
+		"nvme nvme1       Composite 40°C",
+		"nct6775          SYSTIN 35°C  CPU Fan 1200rpm  fan2 0rpm",
+		"acpitz           temp1 28°C  crit 105°C",
+		"x86_pkg_temp     thermal_zone1 63°C  max 90°C crit 100°C",
+	}
+	if !reflect.DeepEqual(text, want) {
+		t.Errorf("renderSensorsPanel =\n%s\nexpected\n%s", strings.Join(text, "\n"), strings.Join(want, "\n"))
+	}
+}
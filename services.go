@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ServiceUnit is a systemd service with the usage of its cgroup, which
+// includes any cgroups below it
+type ServiceUnit struct {
+	Name string
+	// Cgroup is the unit's cgroup path and Slice the path of its parent
+	Cgroup    string
+	Slice     string
+	CPUCores  float64
+	Memory    uint64
+	MemoryMax uint64
+	// Tasks is pids.current, every thread in the cgroup
+	Tasks uint64
+	// Processes counts the processes whose innermost service is this one
+	Processes int
+	// Restarts is systemd's NRestarts, -1 when unknown
+	Restarts int
+}
+
+// ServicesSnapshot is the service list with the cgroup root it came from
+type ServicesSnapshot struct {
+	Root  string
+	Units []ServiceUnit
+	// HasRestarts is false when systemctl couldn't be queried
+	HasRestarts bool
+}
+
+// servicesMsg carries a fresh service list to the model
+type servicesMsg ServicesSnapshot
+
+// Arguments of the systemctl query for restart counts; unit names follow
+var systemctlShowArgs = []string{"show", "--property=Id,NRestarts", "--"}
+
+func updateServices() tea.Cmd {
+	return func() tea.Msg {
+		nodes := readCgroupTree(cgroupRoot(), procfsRoot)
+		cgroupTreeStats.sample(nodes, time.Now())
+
+		var names []string
+		for _, n := range nodes {
+			if isSystemService(n.Path) {
+				names = append(names, path.Base(n.Path))
+			}
+		}
+		restarts := readRestarts(names)
+		return servicesMsg(ServicesSnapshot{
+			Root:        cgroupRoot(),
+			Units:       collectServices(nodes, restarts),
+			HasRestarts: restarts != nil,
+		})
+	}
+}
+
+// isSystemService reports whether a cgroup is a service of the system
+// manager. Services below user@<uid>.service belong to a user manager,
+// which systemctl without --user doesn't know about.
+func isSystemService(cgroup string) bool {
+	return strings.HasSuffix(cgroup, ".service") && serviceCgroup(path.Dir(cgroup)) == ""
+}
+
+// serviceCgroup returns the cgroup of the innermost service that cgroup is
+// in, or "" when it isn't in one
+func serviceCgroup(cgroup string) string {
+	for cgroup != "/" && cgroup != "." && cgroup != "" {
+		if strings.HasSuffix(cgroup, ".service") {
+			return cgroup
+		}
+		cgroup = path.Dir(cgroup)
+	}
+	return ""
+}
+
+// collectServices lists the service cgroups of the tree, assigning each
+// process to its innermost service. restarts maps unit names of the system
+// manager to their restart count.
+func collectServices(nodes []CgroupNode, restarts map[string]int) []ServiceUnit {
+	processes := make(map[string]int)
+	for _, n := range nodes {
+		if unit := serviceCgroup(n.Path); unit != "" {
+			processes[unit] += len(n.Procs)
+		}
+	}
+
+	var units []ServiceUnit
+	for _, n := range nodes {
+		if !strings.HasSuffix(n.Path, ".service") {
+			continue
+		}
+		u := ServiceUnit{
+			Name:      path.Base(n.Path),
+			Cgroup:    n.Path,
+			Slice:     strings.TrimPrefix(path.Dir(n.Path), "/"),
+			CPUCores:  n.CPUCores,
+			Memory:    n.MemoryCurrent,
+			MemoryMax: n.MemoryMax,
+			Tasks:     n.Pids,
+			Processes: processes[n.Path],
+			Restarts:  -1,
+		}
+		if !n.hasPidsCurrent {
+			u.Tasks = uint64(u.Processes)
+		}
+		if count, ok := restarts[u.Name]; ok && isSystemService(n.Path) {
+			u.Restarts = count
+		}
+		units = append(units, u)
+	}
+	return units
+}
+
+// readRestarts asks systemd for the restart count of units, returning nil
+// when systemctl isn't available
+func readRestarts(units []string) map[string]int {
+	if len(units) == 0 {
+		return map[string]int{}
+	}
+	args := append(append([]string{}, systemctlShowArgs...), units...)
+	output, err := runner.Output("systemctl", args...)
+	if err != nil {
+		return nil
+	}
+	return parseSystemctlShow(output)
+}
+
+// parseSystemctlShow parses `systemctl show --property=Id,NRestarts` output,
+// one block of Key=Value lines per unit separated by blank lines
+func parseSystemctlShow(output []byte) map[string]int {
+	restarts := make(map[string]int)
+	for _, block := range strings.Split(string(output), "\n\n") {
+		var id string
+		count := -1
+		for _, line := range strings.Split(block, "\n") {
+			key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "Id":
+				id = value
+			case "NRestarts":
+				if n, err := strconv.Atoi(value); err == nil {
+					count = n
+				}
+			}
+		}
+		if id != "" && count >= 0 {
+			restarts[id] = count
+		}
+	}
+	return restarts
+}
+
+// serviceSort is the column the services view is sorted by
+type serviceSort int
+
+const (
+	serviceSortCPU serviceSort = iota
+	serviceSortMemory
+	serviceSortTasks
+	serviceSortRestarts
+	serviceSortName
+	serviceSortCount
+)
+
+// sortServices returns units sorted by column, largest first except for
+// names, and the other way around when reverse is set
+func sortServices(units []ServiceUnit, column serviceSort, reverse bool) []ServiceUnit {
+	sorted := append([]ServiceUnit(nil), units...)
+	less := func(a, b ServiceUnit) bool {
+		switch column {
+		case serviceSortMemory:
+			if a.Memory != b.Memory {
+				return a.Memory > b.Memory
+			}
+		case serviceSortTasks:
+			if a.Tasks != b.Tasks {
+				return a.Tasks > b.Tasks
+			}
+		case serviceSortRestarts:
+			if a.Restarts != b.Restarts {
+				return a.Restarts > b.Restarts
+			}
+		case serviceSortName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		default:
+			if a.CPUCores != b.CPUCores {
+				return a.CPUCores > b.CPUCores
+			}
+		}
+		return a.Cgroup < b.Cgroup
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if reverse {
+			return less(sorted[j], sorted[i])
+		}
+		return less(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// handleServicesKey changes the sort column of the services view
+func handleServicesKey(m model, key string) (model, bool) {
+	switch key {
+	case ">", ".":
+		m.servicesSort = (m.servicesSort + 1) % serviceSortCount
+	case "<", ",":
+		m.servicesSort = (m.servicesSort + serviceSortCount - 1) % serviceSortCount
+	case "r":
+		m.servicesReverse = !m.servicesReverse
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+// Fixed columns of the services view: UNIT (32) + CPU% (7) + MEMORY (8) +
+// MAX (8) + TASKS (6) + PROCS (6) + RESTARTS (8) + spacing (8) = 83
+const (
+	serviceNameWidth    = 32
+	serviceFixedColumns = 83
+)
+
+// renderServicesView lists the systemd services in the chosen order
+func renderServicesView(m model) []string {
+	snap := m.services
+	if snap.Root == "" {
+		return []string{"(reading the cgroup hierarchy)"}
+	}
+	if len(snap.Units) == 0 {
+		return []string{fmt.Sprintf("(no systemd services under %s)", snap.Root)}
+	}
+
+	sliceWidth := m.width - serviceFixedColumns
+	if sliceWidth < 10 {
+		sliceWidth = 10
+	}
+
+	// Mark the sort column with its direction
+	labels := []string{"CPU%", "MEMORY", "TASKS", "RESTARTS", "UNIT"}
+	arrow := "▼"
+	if m.servicesReverse != (m.servicesSort == serviceSortName) {
+		arrow = "▲"
+	}
+	labels[m.servicesSort] += arrow
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	lines := []string{headerStyle.Render(fmt.Sprintf("%-*s %7s %8s %8s %6s %6s %8s  %s",
+		serviceNameWidth, labels[serviceSortName], labels[serviceSortCPU], labels[serviceSortMemory], "MAX",
+		labels[serviceSortTasks], "PROCS", labels[serviceSortRestarts], "SLICE"))}
+
+	for _, u := range sortServices(snap.Units, m.servicesSort, m.servicesReverse) {
+		limit := "-"
+		memoryStyle := lipgloss.NewStyle()
+		if u.MemoryMax > 0 {
+			limit = formatBytes(float64(u.MemoryMax))
+			memoryStyle = getColorStyle(percentOf(u.Memory, u.MemoryMax))
+		}
+		restarts := "-"
+		if u.Restarts >= 0 {
+			restarts = strconv.Itoa(u.Restarts)
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %7.1f %s %8s %6d %6d %8s  %s",
+			serviceNameWidth, truncateLeft(u.Name, serviceNameWidth), u.CPUCores*100,
+			memoryStyle.Render(fmt.Sprintf("%8s", formatBytes(float64(u.Memory)))), limit,
+			u.Tasks, u.Processes, restarts, truncateLeft(u.Slice, sliceWidth)))
+	}
+
+	lines = append(lines, "", "< and > change the sort column, r reverses it")
+	if !snap.HasRestarts {
+		lines = append(lines, "restart counts need systemctl")
+	}
+	return lines
+}
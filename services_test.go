@@ -0,0 +1,99 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCollectServices(t *testing.T) {
+	nodes := readCgroupTree(filepath.Join("testdata", "cgroup", "tree"), filepath.Join("testdata", "proc"))
+	restarts := map[string]int{"nginx.service": 3, "dnsmasq.service": 0, "dbus.service": 7}
+	units := collectServices(nodes, restarts)
+
+	want := []ServiceUnit{
+		{Name: "dnsmasq.service", Cgroup: "/system.slice/dnsmasq.service", Slice: "system.slice",
+			Memory: 8 << 20, Tasks: 1, Processes: 1, Restarts: 0},
+		{Name: "nginx.service", Cgroup: "/system.slice/nginx.service", Slice: "system.slice",
+			Memory: 128 << 20, MemoryMax: 256 << 20, Tasks: 2, Processes: 2, Restarts: 3},
+		// The user manager's process is its own; dbus.service's belongs to
+		// the innermost service, whose restarts systemctl can't report
+		{Name: "user@1000.service", Cgroup: "/user.slice/user-1000.slice/user@1000.service", Slice: "user.slice/user-1000.slice",
+			Memory: 16 << 20, Tasks: 4, Processes: 1, Restarts: -1},
+		{Name: "dbus.service", Cgroup: "/user.slice/user-1000.slice/user@1000.service/app.slice/dbus.service",
+			Slice: "user.slice/user-1000.slice/user@1000.service/app.slice", Memory: 4 << 20, Tasks: 1, Processes: 1, Restarts: -1},
+	}
+	if !reflect.DeepEqual(units, want) {
+		t.Errorf("collectServices =\n%+v\nexpected\n%+v", units, want)
+	}
+}
+
+func TestParseSystemctlShow(t *testing.T) {
+	output := "NRestarts=2\nId=nginx.service\n\nId=dnsmasq.service\nNRestarts=0\n\nId=gone.service\nNRestarts=\n"
+	want := map[string]int{"nginx.service": 2, "dnsmasq.service": 0}
+	if got := parseSystemctlShow([]byte(output)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSystemctlShow = %v; expected %v", got, want)
+	}
+}
+
+func TestServicesViewSort(t *testing.T) {
+	m := model{width: 120, height: 20, view: viewServices, services: ServicesSnapshot{
+		Root:        "testdata",
+		HasRestarts: true,
+		Units: []ServiceUnit{
+			{Name: "a.service", Cgroup: "/a.service", CPUCores: 0.1, Memory: 300 << 20, Restarts: 1},
+			{Name: "b.service", Cgroup: "/b.service", CPUCores: 0.5, Memory: 100 << 20, Restarts: 0},
+		},
+	}}
+	order := func() string {
+		var names []string
+		for _, line := range strings.Split(stripAnsiCodes(m.View()), "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 && strings.HasSuffix(fields[0], ".service") {
+				names = append(names, fields[0])
+			}
+		}
+		return strings.Join(names, " ")
+	}
+	press := func(key string) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = next.(model)
+	}
+
+	if got := order(); got != "b.service a.service" {
+		t.Errorf("default order %q; expected busiest first", got)
+	}
+	press(">")
+	if got := order(); got != "a.service b.service" {
+		t.Errorf("by memory %q; expected a.service first", got)
+	}
+	press("r")
+	if got := order(); got != "b.service a.service" {
+		t.Errorf("by memory reversed %q; expected b.service first", got)
+	}
+	press("<")
+	press("<")
+	if m.servicesSort != serviceSortName {
+		t.Fatalf("< twice from memory selected column %d; expected wrapping to the name", m.servicesSort)
+	}
+	if got := order(); got != "b.service a.service" {
+		t.Errorf("by name reversed %q; expected b.service first", got)
+	}
+}
+
+func TestSortServicesByName(t *testing.T) {
+	units := []ServiceUnit{
+		{Name: "zookeeper.service", Cgroup: "system.slice/zookeeper.service"},
+		{Name: "app.service", Cgroup: "user.slice/user-1000.slice/user@1000.service/app.slice/app.service"},
+		{Name: "cron.service", Cgroup: "system.slice/cron.service"},
+	}
+	var names []string
+	for _, u := range sortServices(units, serviceSortName, false) {
+		names = append(names, u.Name)
+	}
+	if got := strings.Join(names, " "); got != "app.service cron.service zookeeper.service" {
+		t.Errorf("by name %q; expected alphabetical unit names regardless of slice", got)
+	}
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CPUTopology places one logical CPU on the machine
+type CPUTopology struct {
+	// Package is the physical socket
+	Package int
+	// Node is the NUMA node, -1 when the kernel doesn't report one
+	Node int
+	// CoreID identifies the physical core within the package
+	CoreID int
+	// Siblings are the logical CPUs sharing the physical core, this one included
+	Siblings []int
+	Caches   []CPUCache
+}
+
+// CPUCache is one cache level seen by a logical CPU
+type CPUCache struct {
+	Level int
+	// Type is "Data", "Instruction" or "Unified"
+	Type string
+	Size uint64
+	// SharedCPUs is the kernel's cpulist of CPUs sharing this cache instance
+	SharedCPUs string
+}
+
+// Name is the conventional short name, e.g. "L1d" or "L3"
+func (c CPUCache) Name() string {
+	name := fmt.Sprintf("L%d", c.Level)
+	switch c.Type {
+	case "Data":
+		name += "d"
+	case "Instruction":
+		name += "i"
+	}
+	return name
+}
+
+// CPUFreq is a logical CPU's clock and cpufreq policy. Frequencies are in kHz
+// and zero when cpufreq isn't available (e.g. in most VMs).
+type CPUFreq struct {
+	Cur uint64
+	// Min and Max are the hardware limits
+	Min uint64
+	Max uint64
+	// Limit is the policy's scaling_max_freq, below Max when clocks are capped
+	Limit    uint64
+	Governor string
+}
+
+// cpuDir is the sysfs directory of logical CPU n
+func cpuDir(root string, n int) string {
+	return filepath.Join(root, "devices", "system", "cpu", fmt.Sprintf("cpu%d", n))
+}
+
+// readSysfsInt reads a file holding a single integer
+func readSysfsInt(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// readSysfsString reads a single-line file
+func readSysfsString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// topologyCache holds the topology, which only changes on CPU hotplug, so the
+// per-CPU cache files aren't re-read every tick
+var topologyCache struct {
+	mu    sync.Mutex
+	root  string
+	count int
+	value []CPUTopology
+}
+
+// cachedCPUTopology returns readCPUTopology(root, count), reading it again
+// only when the root or CPU count changes
+func cachedCPUTopology(root string, count int) []CPUTopology {
+	topologyCache.mu.Lock()
+	defer topologyCache.mu.Unlock()
+
+	if topologyCache.value == nil || topologyCache.root != root || topologyCache.count != count {
+		topologyCache.root, topologyCache.count = root, count
+		topologyCache.value = readCPUTopology(root, count)
+	}
+	return topologyCache.value
+}
+
+// readCPUTopology reads <root>/devices/system/cpu/cpu<N> for the first count
+// logical CPUs. CPUs without topology files are placed on package 0 as their
+// own core.
+func readCPUTopology(root string, count int) []CPUTopology {
+	result := make([]CPUTopology, count)
+	for i := range result {
+		dir := cpuDir(root, i)
+		result[i] = CPUTopology{Node: -1, CoreID: i, Siblings: []int{i}}
+
+		if pkg, err := readSysfsInt(filepath.Join(dir, "topology", "physical_package_id")); err == nil && pkg >= 0 {
+			result[i].Package = pkg
+		}
+		if core, err := readSysfsInt(filepath.Join(dir, "topology", "core_id")); err == nil {
+			result[i].CoreID = core
+		}
+		if siblings := parseCPUList(readSysfsString(filepath.Join(dir, "topology", "thread_siblings_list"))); len(siblings) > 0 {
+			result[i].Siblings = siblings
+		}
+
+		// The node shows up as a nodeN symlink next to topology/
+		nodes, _ := filepath.Glob(filepath.Join(dir, "node[0-9]*"))
+		for _, path := range nodes {
+			if node, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(path), "node")); err == nil {
+				result[i].Node = node
+				break
+			}
+		}
+
+		result[i].Caches = readCPUCaches(filepath.Join(dir, "cache"))
+	}
+	return result
+}
+
+// readCPUCaches reads the cache/index* directories of one CPU
+func readCPUCaches(dir string) []CPUCache {
+	indexes, _ := filepath.Glob(filepath.Join(dir, "index[0-9]*"))
+	sort.Strings(indexes)
+
+	var caches []CPUCache
+	for _, index := range indexes {
+		level, err := readSysfsInt(filepath.Join(index, "level"))
+		if err != nil {
+			continue
+		}
+		size, err := parseCacheSize(readSysfsString(filepath.Join(index, "size")))
+		if err != nil {
+			continue
+		}
+		caches = append(caches, CPUCache{
+			Level:      level,
+			Type:       readSysfsString(filepath.Join(index, "type")),
+			Size:       size,
+			SharedCPUs: readSysfsString(filepath.Join(index, "shared_cpu_list")),
+		})
+	}
+	return caches
+}
+
+// parseCacheSize parses sizes such as "48K" or "30720K" into bytes
+func parseCacheSize(s string) (uint64, error) {
+	multiplier := uint64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(s, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(s, "G"):
+		multiplier = 1 << 30
+	}
+	n, err := strconv.ParseUint(strings.TrimRight(s, "KMG"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cache size %q: %w", s, err)
+	}
+	return n * multiplier, nil
+}
+
+// parseCPUList expands a kernel cpulist such as "0-3,8,10-11"
+func parseCPUList(s string) []int {
+	var cpus []int
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil {
+			continue
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(hi); err != nil {
+				continue
+			}
+		}
+		for c := first; c <= last; c++ {
+			cpus = append(cpus, c)
+		}
+	}
+	return cpus
+}
+
+// readCPUFreq reads the cpufreq directory of the first count logical CPUs
+func readCPUFreq(root string, count int) []CPUFreq {
+	result := make([]CPUFreq, count)
+	for i := range result {
+		dir := filepath.Join(cpuDir(root, i), "cpufreq")
+		khz := func(names ...string) uint64 {
+			for _, name := range names {
+				if v, err := readSysfsInt(filepath.Join(dir, name)); err == nil && v > 0 {
+					return uint64(v)
+				}
+			}
+			return 0
+		}
+		result[i] = CPUFreq{
+			Cur:      khz("scaling_cur_freq", "cpuinfo_cur_freq"),
+			Min:      khz("cpuinfo_min_freq"),
+			Max:      khz("cpuinfo_max_freq"),
+			Limit:    khz("scaling_max_freq"),
+			Governor: readSysfsString(filepath.Join(dir, "scaling_governor")),
+		}
+	}
+	return result
+}
+
+// formatFreq formats a kHz frequency as GHz, e.g. "3.20G"
+func formatFreq(khz uint64) string {
+	return fmt.Sprintf("%.2fG", float64(khz)/1e6)
+}
+
+// capped reports whether the policy keeps the CPU below its hardware maximum
+func (f CPUFreq) capped() bool {
+	return f.Limit > 0 && f.Max > 0 && f.Limit < f.Max
+}
+
+// formatCPUList joins CPU numbers with commas
+func formatCPUList(cpus []int) string {
+	parts := make([]string, len(cpus))
+	for i, c := range cpus {
+		parts[i] = strconv.Itoa(c)
+	}
+	return strings.Join(parts, ",")
+}
+
+// summarizeCaches lists each cache level once with its size and how many
+// instances the machine has, e.g. "L3 32.0M x2"
+func summarizeCaches(topology []CPUTopology) []string {
+	type kind struct {
+		name string
+		size uint64
+	}
+	instances := make(map[kind]map[string]bool)
+	var order []kind
+	for _, t := range topology {
+		for _, c := range t.Caches {
+			k := kind{c.Name(), c.Size}
+			if instances[k] == nil {
+				instances[k] = make(map[string]bool)
+				order = append(order, k)
+			}
+			instances[k][c.SharedCPUs] = true
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool { return order[i].name < order[j].name })
+	result := make([]string, len(order))
+	for i, k := range order {
+		result[i] = fmt.Sprintf("%s %s x%d", k.name, formatBytes(float64(k.size)), len(instances[k]))
+	}
+	return result
+}
+
+// renderTopologyView lists every logical CPU grouped by socket and physical
+// core, so SMT siblings are adjacent, with its clock and cpufreq policy. A
+// busy CPU running below half its maximum clock is highlighted, as is a
+// policy limit below the hardware maximum.
+func renderTopologyView(m model) []string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	boldStyle := lipgloss.NewStyle().Bold(true)
+
+	count := len(m.stats.CPUCores)
+	topology := m.stats.CPUTopology
+	if count == 0 || len(topology) != count {
+		return []string{"(CPU topology not available)"}
+	}
+	freq := m.stats.CPUFreq
+	if len(freq) != count {
+		freq = make([]CPUFreq, count)
+	}
+
+	packages := make(map[int]bool)
+	nodes := make(map[int]bool)
+	cores := make(map[[2]int]bool)
+	governors := make(map[string]bool)
+	for i, t := range topology {
+		packages[t.Package] = true
+		if t.Node >= 0 {
+			nodes[t.Node] = true
+		}
+		cores[[2]int{t.Package, t.CoreID}] = true
+		if freq[i].Governor != "" {
+			governors[freq[i].Governor] = true
+		}
+	}
+
+	summary := fmt.Sprintf("%d sockets, %d NUMA nodes, %d cores, %d threads", len(packages), len(nodes), len(cores), count)
+	if len(governors) > 0 {
+		names := make([]string, 0, len(governors))
+		for g := range governors {
+			names = append(names, g)
+		}
+		sort.Strings(names)
+		summary += ", governor " + strings.Join(names, "/")
+	}
+	lines := []string{boldStyle.Render(summary)}
+	if caches := summarizeCaches(topology); len(caches) > 0 {
+		lines = append(lines, "Caches: "+strings.Join(caches, "  "))
+	}
+	lines = append(lines, "")
+
+	order := make([]int, count)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ta, tb := topology[order[a]], topology[order[b]]
+		if ta.Package != tb.Package {
+			return ta.Package < tb.Package
+		}
+		return ta.CoreID < tb.CoreID
+	})
+
+	format := "%-7s %4s %4s %5s %-10s %7s %7s %7s %7s %-12s %6s"
+	lines = append(lines, headerStyle.Render(fmt.Sprintf(format,
+		"CPU", "PKG", "NODE", "CORE", "SIBLINGS", "CUR", "MIN", "MAX", "LIMIT", "GOVERNOR", "USAGE")))
+
+	for _, i := range order {
+		t, f := topology[i], freq[i]
+		node := "-"
+		if t.Node >= 0 {
+			node = strconv.Itoa(t.Node)
+		}
+		khz := func(v uint64) string {
+			if v == 0 {
+				return "-"
+			}
+			return formatFreq(v)
+		}
+
+		cur := fmt.Sprintf("%7s", khz(f.Cur))
+		if f.Max > 0 && f.Cur > 0 && f.Cur*2 < f.Max && m.stats.CPUCores[i] >= 50 {
+			cur = yellowStyle.Render(cur)
+		}
+		limit := fmt.Sprintf("%7s", khz(f.Limit))
+		if f.capped() {
+			limit = redStyle.Render(limit)
+		}
+		governor := f.Governor
+		if governor == "" {
+			governor = "-"
+		}
+
+		lines = append(lines, fmt.Sprintf("%-7s %4d %4s %5d %-10s %s %7s %7s %s %-12s %5.1f%%",
+			coreLabel(i, count), t.Package, node, t.CoreID, truncateLeft(formatCPUList(t.Siblings), 10),
+			cur, khz(f.Min), khz(f.Max), limit, governor, m.stats.CPUCores[i]))
+	}
+	return lines
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCPUTopology(t *testing.T) {
+	// Two sockets with one SMT core each; cpu4 has no files
+	got := readCPUTopology(filepath.Join("testdata", "sysfs", "topology"), 5)
+	l1 := func(shared string) CPUCache {
+		return CPUCache{Level: 1, Type: "Data", Size: 48 << 10, SharedCPUs: shared}
+	}
+	l3 := func(shared string) CPUCache {
+		return CPUCache{Level: 3, Type: "Unified", Size: 30 << 20, SharedCPUs: shared}
+	}
+	want := []CPUTopology{
+		{Package: 0, Node: 0, CoreID: 0, Siblings: []int{0, 1}, Caches: []CPUCache{l1("0-1"), l3("0-1")}},
+		{Package: 0, Node: 0, CoreID: 0, Siblings: []int{0, 1}},
+		{Package: 1, Node: 1, CoreID: 0, Siblings: []int{2, 3}, Caches: []CPUCache{l1("2-3"), l3("2-3")}},
+		{Package: 1, Node: 1, CoreID: 0, Siblings: []int{2, 3}},
+		{Package: 0, Node: -1, CoreID: 4, Siblings: []int{4}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readCPUTopology =\n%+v\nexpected\n%+v", got, want)
+	}
+
+	if caches := summarizeCaches(got); !reflect.DeepEqual(caches, []string{"L1d 48.0K x2", "L3 30.0M x2"}) {
+		t.Errorf("summarizeCaches = %q", caches)
+	}
+}
+
+func TestReadCPUFreq(t *testing.T) {
+	got := readCPUFreq(filepath.Join("testdata", "sysfs", "topology"), 2)
+	want := []CPUFreq{
+		{Cur: 800000, Min: 400000, Max: 4800000, Limit: 2000000, Governor: "powersave"},
+		{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readCPUFreq = %+v; expected %+v", got, want)
+	}
+	if !got[0].capped() || got[1].capped() {
+		t.Errorf("capped() = %v, %v; expected only cpu0 to be capped", got[0].capped(), got[1].capped())
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	tests := map[string][]int{
+		"":            nil,
+		"3":           {3},
+		"0-3":         {0, 1, 2, 3},
+		"0,8":         {0, 8},
+		"0-1,8,10-11": {0, 1, 8, 10, 11},
+	}
+	for input, want := range tests {
+		if got := parseCPUList(input); !reflect.DeepEqual(got, want) {
+			t.Errorf("parseCPUList(%q) = %v; expected %v", input, got, want)
+		}
+	}
+}
+
+func TestRenderTopologyView(t *testing.T) {
+	root := filepath.Join("testdata", "sysfs", "topology")
+	m := model{
+		width:  120,
+		height: 24,
+		stats: SystemStats{
+			CPUCores:    []float64{90, 10, 20, 30},
+			CPUTopology: readCPUTopology(root, 4),
+			CPUFreq:     readCPUFreq(root, 4),
+		},
+	}
+
+	lines := renderTopologyView(m)
+	if got := stripAnsiCodes(lines[0]); got != "2 sockets, 2 NUMA nodes, 2 cores, 4 threads, governor powersave" {
+		t.Errorf("summary = %q", got)
+	}
+
+	// The busy cpu0 runs at 0.80G of 4.80G under a 2.00G limit
+	var row string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "CPU00 ") {
+			row = line
+		}
+	}
+	for _, want := range []string{yellowStyle.Render("  0.80G"), redStyle.Render("  2.00G"), "0,1", "powersave"} {
+		if !strings.Contains(row, want) {
+			t.Errorf("cpu0 row %q does not contain %q", row, want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// viewMode selects what fills the screen: the dashboard or a full-screen view
+type viewMode int
+
+const (
+	viewDashboard viewMode = iota
+	viewConnections
+	viewTopology
+	viewCgroups
+	viewServices
+	viewEvents
+	viewProcessLog
+)
+
+// viewDef describes a full-screen view. render returns every line of the view
+// and the model scrolls through them; refresh, if set, fetches the data the
+// view needs and is run when the view is opened and on every tick while it
+// is shown. keys, if set, gets the keys the view handles itself before the
+// default scrolling, reporting whether it consumed the key.
+type viewDef struct {
+	title   string
+	key     string
+	render  func(m model) []string
+	refresh func() tea.Cmd
+	keys    func(m model, key string) (model, bool)
+}
+
+// views lists the views in tab order; viewMode values index into it
+var views = []viewDef{
+	viewDashboard:   {title: "Dashboard", key: "1"},
+	viewConnections: {title: "Connections", key: "2", render: renderConnectionsView, refresh: updateConnections},
+	viewTopology:    {title: "Topology", key: "3", render: renderTopologyView},
+	viewCgroups:     {title: "Cgroups", key: "4", render: renderCgroupView, refresh: updateCgroupTree, keys: handleCgroupKey},
+	viewServices:    {title: "Services", key: "5", render: renderServicesView, refresh: updateServices, keys: handleServicesKey},
+	viewEvents:      {title: "Events", key: "6", render: renderEventsView},
+	viewProcessLog:  {title: "Process log", key: "7", render: renderProcessLogView, keys: handleProcessLogKey},
+}
+
+// switchView opens v, resetting the scroll position
+func (m model) switchView(v viewMode) (model, tea.Cmd) {
+	m.view = v
+	m.scroll = 0
+	if refresh := views[v].refresh; refresh != nil {
+		return m, refresh()
+	}
+	return m, nil
+}
+
+// handleViewKey handles view selection and scrolling keys, reporting whether
+// the key was consumed
+func (m model) handleViewKey(key string) (model, tea.Cmd, bool) {
+	for i, v := range views {
+		if v.key == key {
+			m, cmd := m.switchView(viewMode(i))
+			return m, cmd, true
+		}
+	}
+
+	switch key {
+	case "tab":
+		m, cmd := m.switchView((m.view + 1) % viewMode(len(views)))
+		return m, cmd, true
+	case "shift+tab":
+		m, cmd := m.switchView((m.view + viewMode(len(views)) - 1) % viewMode(len(views)))
+		return m, cmd, true
+	}
+
+	if m.view == viewDashboard {
+		return m, nil, false
+	}
+	if keys := views[m.view].keys; keys != nil {
+		if m, ok := keys(m, key); ok {
+			return m, nil, true
+		}
+	}
+
+	page := m.viewHeight()
+	switch key {
+	case "up", "k":
+		m.scroll--
+	case "down", "j":
+		m.scroll++
+	case "pgup":
+		m.scroll -= page
+	case "pgdown", " ":
+		m.scroll += page
+	case "home", "g":
+		m.scroll = 0
+	case "end", "G":
+		m.scroll = len(views[m.view].render(m))
+	default:
+		return m, nil, false
+	}
+
+	if maxScroll := len(views[m.view].render(m)) - page; m.scroll > maxScroll {
+		m.scroll = maxScroll
+	}
+	if m.scroll < 0 {
+		m.scroll = 0
+	}
+	return m, nil, true
+}
+
+// viewHeight is the number of content lines below the alert banner and the
+// tab bar
+func (m model) viewHeight() int {
+	height := m.height
+	if height == 0 {
+		height = 24
+	}
+	height -= len(m.renderAlertBanner())
+	if height < 2 {
+		return 1
+	}
+	return height - 1
+}
+
+// renderTabBar shows every view with the current one highlighted
+func (m model) renderTabBar() string {
+	activeStyle := lipgloss.NewStyle().Bold(true).Reverse(true)
+	inactiveStyle := lipgloss.NewStyle().Faint(true)
+
+	var tabs []string
+	for i, v := range views {
+		label := " " + v.key + " " + v.title + " "
+		if viewMode(i) == m.view {
+			tabs = append(tabs, activeStyle.Render(label))
+		} else {
+			tabs = append(tabs, inactiveStyle.Render(label))
+		}
+	}
+	return strings.Join(tabs, " ")
+}
+
+// renderView renders the current full-screen view, scrolled to m.scroll
+func (m model) renderView() string {
+	lines := views[m.view].render(m)
+
+	height := m.viewHeight()
+	start := m.scroll
+	if maxStart := len(lines) - height; start > maxStart {
+		start = maxStart
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + height
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var s strings.Builder
+	for _, line := range m.renderAlertBanner() {
+		s.WriteString(line + "\n")
+	}
+	s.WriteString(m.renderTabBar() + "\n")
+	for _, line := range lines[start:end] {
+		s.WriteString(line + "\n")
+	}
+	return s.String()
+}
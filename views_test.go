@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestViewKeys(t *testing.T) {
+	m := model{width: 80, height: 10, panels: map[string]bool{}}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = next.(model)
+	if m.view != viewConnections {
+		t.Fatalf("tab moved to view %d; expected the connections view", m.view)
+	}
+
+	// Dashboard keys leave the dashboard alone in other views
+	for _, key := range []string{"n", "s"} {
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = next.(model)
+	}
+	if m.panels["network"] || m.coreGrouped {
+		t.Errorf("dashboard keys changed the dashboard from the connections view: panels %v, grouped %v", m.panels, m.coreGrouped)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	m = next.(model)
+	if m.view != viewDashboard {
+		t.Errorf("\"1\" moved to view %d; expected the dashboard", m.view)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m = next.(model)
+	if !m.panels["network"] {
+		t.Error("\"n\" on the dashboard didn't show the network panel")
+	}
+}
+
+func TestRenderViewScrolls(t *testing.T) {
+	m := model{width: 80, height: 5, view: viewConnections}
+	for i := 0; i < 20; i++ {
+		m.connections.Connections = append(m.connections.Connections, Connection{Proto: "unix", Local: "/run/s", State: "UNCONN"})
+	}
+
+	m.scroll = 1000
+	lines := strings.Split(strings.TrimSuffix(m.View(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("view has %d lines; expected the terminal height of 5", len(lines))
+	}
+	if !strings.Contains(stripAnsiCodes(lines[len(lines)-1]), "/run/s") {
+		t.Errorf("scrolling past the end should pin the last line, got %q", lines[len(lines)-1])
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// historyLength is how many samples are kept per series for sparklines
+const historyLength = 120
+
+// series is a fixed-capacity buffer of recent samples, oldest first
+type series struct {
+	values []float64
+}
+
+// push appends v, dropping the oldest sample once historyLength is reached
+func (s *series) push(v float64) {
+	if len(s.values) == historyLength {
+		copy(s.values, s.values[1:])
+		s.values[len(s.values)-1] = v
+		return
+	}
+	s.values = append(s.values, v)
+}
+
+// last returns up to n of the most recent samples
+func (s *series) last(n int) []float64 {
+	if s == nil {
+		return nil
+	}
+	if n >= len(s.values) {
+		return s.values
+	}
+	return s.values[len(s.values)-n:]
+}
+
+// history maps series names (e.g. "disk:sda:read") to their recent samples
+type history map[string]*series
+
+// record pushes v onto the named series, creating it if needed
+func (h history) record(name string, v float64) {
+	s, ok := h[name]
+	if !ok {
+		s = &series{}
+		h[name] = s
+	}
+	s.push(v)
+}
+
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders the most recent width values scaled against max, right
+// aligned and padded with spaces. A max of 0 scales to the largest value.
+func sparkline(values []float64, width int, max float64) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	if max <= 0 {
+		for _, v := range values {
+			if v > max {
+				max = v
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", width-len(values)))
+	for _, v := range values {
+		idx := 0
+		if max > 0 && v > 0 {
+			idx = int(v / max * float64(len(sparkRunes)-1))
+			if idx >= len(sparkRunes) {
+				idx = len(sparkRunes) - 1
+			}
+		}
+		b.WriteRune(sparkRunes[idx])
+	}
+	return b.String()
+}
+
+// formatBytes formats a byte count with binary units, e.g. "1.5G"
+func formatBytes(b float64) string {
+	const unit = 1024.0
+	if b < unit {
+		return fmt.Sprintf("%.0fB", b)
+	}
+	suffixes := "KMGTPE"
+	exp := 0
+	for b >= unit*unit && exp < len(suffixes)-1 {
+		b /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", b/unit, suffixes[exp])
+}
+
+// formatDuration formats a duration in its two largest units, e.g. "3h20m"
+// or "2d04h"
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd%02dh", int(d.Hours())/24, int(d.Hours())%24)
+}
+
+// barSegment is one colored part of a stacked bar, as a percentage of the whole
+type barSegment struct {
+	percent float64
+	style   lipgloss.Style
+}
+
+// createStackedBar renders segments left to right and fills the remainder
+// with the empty bar character. Segment edges are placed on cumulative
+// percentages so rounding errors don't add up across segments.
+func createStackedBar(segments []barSegment, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	var cumulative float64
+	pos := 0
+	for _, seg := range segments {
+		if seg.percent <= 0 {
+			continue
+		}
+		cumulative += seg.percent
+		if cumulative > 100 {
+			cumulative = 100
+		}
+		end := int(cumulative / 100.0 * float64(width))
+		if end > pos {
+			b.WriteString(seg.style.Render(strings.Repeat("█", end-pos)))
+			pos = end
+		}
+	}
+	b.WriteString(strings.Repeat("░", width-pos))
+	return b.String()
+}
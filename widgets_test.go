@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		width  int
+		max    float64
+		want   string
+	}{
+		{"auto scaled", []float64{0, 50, 100}, 3, 0, "▁▄█"},
+		{"padded on the left", []float64{100}, 3, 100, "  █"},
+		{"keeps the newest values", []float64{100, 0, 0}, 2, 100, "▁▁"},
+		{"clamped above max", []float64{250}, 1, 100, "█"},
+		{"zero width", []float64{1}, 0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(tt.values, tt.width, tt.max); got != tt.want {
+				t.Errorf("sparkline(%v, %d, %v) = %q; expected %q", tt.values, tt.width, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeriesKeepsHistoryLength(t *testing.T) {
+	var s series
+	for i := 0; i < historyLength+5; i++ {
+		s.push(float64(i))
+	}
+	if len(s.values) != historyLength || s.values[0] != 5 {
+		t.Errorf("series has %d values starting at %v; expected %d starting at 5", len(s.values), s.values[0], historyLength)
+	}
+	if last := s.last(2); len(last) != 2 || last[1] != historyLength+4 {
+		t.Errorf("last(2) = %v", last)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[float64]string{
+		0:               "0B",
+		1023:            "1023B",
+		1536:            "1.5K",
+		3 << 20:         "3.0M",
+		5 * (1 << 30):   "5.0G",
+		1.5 * (1 << 40): "1.5T",
+	}
+	for input, want := range tests {
+		if got := formatBytes(input); got != want {
+			t.Errorf("formatBytes(%v) = %q; expected %q", input, got, want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		42 * time.Second:              "42s",
+		5*time.Minute + 3*time.Second: "5m03s",
+		3*time.Hour + 20*time.Minute:  "3h20m",
+		50*time.Hour + 30*time.Minute: "2d02h",
+	}
+	for input, want := range tests {
+		if got := formatDuration(input); got != want {
+			t.Errorf("formatDuration(%v) = %q; expected %q", input, got, want)
+		}
+	}
+}
+
+func TestCreateStackedBar(t *testing.T) {
+	bar := stripAnsiCodes(createStackedBar([]barSegment{
+		{percent: 25, style: greenStyle},
+		{percent: 12.5, style: yellowStyle},
+		{percent: 0, style: redStyle},
+		{percent: 37.5, style: redStyle},
+	}, 8))
+	if bar != "██████░░" {
+		t.Errorf("createStackedBar = %q; expected 6 filled cells and 2 empty", bar)
+	}
+
+	if bar := stripAnsiCodes(createStackedBar([]barSegment{{percent: 150, style: redStyle}}, 4)); bar != "████" {
+		t.Errorf("createStackedBar over 100%% = %q; expected a full bar", bar)
+	}
+}